@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Attach creates new instance of Proxy backed by the already running tor
+// demon, e.g. the system tor or a tor sidecar container, instead of
+// launching a new one.
+//
+// The socksAddr and controlAddr are either "host:port" of TCP listeners
+// or "unix:/path" of unix domain sockets. If controlAddr is empty,
+// the Proxy is created without the Controller.
+//
+// Close of the Proxy closes only the control connection, the tor demon
+// keeps running. Options configuring the tor demon are ignored.
+func Attach(ctx context.Context, socksAddr, controlAddr string, auth ControlAuth, ops ...Option) (*Proxy, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	state := newAttachState(ops)
+
+	addr, err := parseEndpoint(socksAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl, err := attachController(ctx, controlAddr, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	closeFunc := makeDetachFunc(ctrl)
+
+	prx, err := openSOCKS5Proxy(addr, state.forwardDialer, ctrl, closeFunc)
+	if err != nil {
+		_ = closeFunc()
+
+		const format = "tornado: cannot create proxy instance: %v"
+
+		return nil, fmt.Errorf(format, err)
+	}
+
+	prx.retryPolicy = state.retryPolicy
+
+	return prx, nil
+}
+
+// AttachPool creates new instance of Pool backed by the already running
+// tor demon, each of socksAddrs becomes a proxy of the pool, see Attach.
+func AttachPool(ctx context.Context, socksAddrs []string, controlAddr string, auth ControlAuth, ops ...Option) (*Pool, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if len(socksAddrs) == 0 {
+		return nil, errors.New("tornado: at least one socks address is required")
+	}
+
+	state := newAttachState(ops)
+
+	addrs := make([]endpoint, 0, len(socksAddrs))
+
+	for _, socksAddr := range socksAddrs {
+		addr, err := parseEndpoint(socksAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	ctrl, err := attachController(ctx, controlAddr, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := newFreePool(len(addrs), ctrl, makeDetachFunc(ctrl))
+	pool.retryPolicy = state.retryPolicy
+	pool.balancer = state.balancer
+
+	for _, addr := range addrs {
+		prx, err := openSOCKS5Proxy(addr, state.forwardDialer, ctrl, nil)
+		if err != nil {
+			_ = pool.Close()
+
+			const format = "tornado: cannot create proxy instance for pool: %v"
+
+			return nil, fmt.Errorf(format, err)
+		}
+
+		prx.retryPolicy = state.retryPolicy
+		pool.add(prx)
+	}
+
+	return pool, nil
+}
+
+func newAttachState(ops []Option) options {
+	var state options
+
+	for _, option := range ops {
+		option.apply(&state)
+	}
+
+	return state
+}
+
+// attachController connects to the control port of the running tor demon,
+// it returns nil Controller if the address is empty.
+func attachController(ctx context.Context, controlAddr string, auth ControlAuth) (*Controller, error) {
+	if controlAddr == "" {
+		return nil, nil
+	}
+
+	addr, err := parseEndpoint(controlAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl, err := dialController(ctx, addr, auth)
+	if err != nil {
+		const format = "tornado: cannot attach to tor demon: %v"
+		return nil, fmt.Errorf(format, err)
+	}
+
+	return ctrl, nil
+}
+
+// makeDetachFunc returns the close function, which closes the control
+// connection, but keeps the tor demon running.
+func makeDetachFunc(ctrl *Controller) func() error {
+	return func() error {
+		if ctrl == nil {
+			return nil
+		}
+
+		return ctrl.Close()
+	}
+}
+
+// parseEndpoint parses the address in the "host:port" or "unix:/path"
+// format.
+func parseEndpoint(addr string) (endpoint, error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return endpoint{network: "unix", address: path}, nil
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		const format = "tornado: invalid address %q: %v"
+		return endpoint{}, fmt.Errorf(format, addr, err)
+	}
+
+	return endpoint{network: "tcp", address: addr}, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"testing"
+)
+
+// newTestControlServer starts a fake control port on the TCP listener,
+// which records received commands and replies "250 OK" to each of them.
+func newTestControlServer(t *testing.T) (addr string, commands <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	received := make(chan string, 16)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				srv := textproto.NewConn(conn)
+				defer srv.Close()
+
+				for {
+					line, err := srv.ReadLine()
+					if err != nil {
+						return
+					}
+
+					received <- line
+
+					_ = srv.PrintfLine("250 OK")
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestAttach(t *testing.T) {
+	t.Parallel()
+	t.Run("Should use running tor demon for dials and control", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		ctx := context.Background()
+		socks, requests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+		control, commands := newTestControlServer(t)
+
+		// act
+		prx, err := Attach(ctx, socks.address, control, PasswordAuth(`pass"word`))
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		conn, err := prx.DialContext(ctx, "tcp", "example.com:443")
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+		defer conn.Close()
+
+		if err := prx.NewIdentity(ctx); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if err := prx.Close(); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		// assert
+		if got := <-commands; got != `AUTHENTICATE "pass\"word"` {
+			t.Fatalf("unexpected authentication command %q", got)
+		}
+
+		if got := <-commands; got != "SIGNAL NEWNYM" {
+			t.Fatalf("unexpected command %q", got)
+		}
+
+		if req := <-requests; req.host != "example.com" || req.port != 443 {
+			t.Fatalf("unexpected request %+v", req)
+		}
+	})
+
+	t.Run("Should create proxy without controller", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		socks, _ := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+
+		// act
+		prx, err := Attach(context.Background(), socks.address, "", nil)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+		defer prx.Close()
+
+		// assert
+		if err := prx.NewIdentity(context.Background()); err != ErrNoController {
+			t.Fatalf("ErrNoController error was expected, but got another one: %v", err)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// A Balancer chooses the proxy of the pool for each connection made by
+// the FloatingProxy, see WithBalancer.
+//
+// The methods of the Balancer are called concurrently.
+type Balancer interface {
+	// Next returns one of the proxies for the next connection, the list is
+	// never empty and must not be modified.
+	Next(proxies []*Proxy) *Proxy
+}
+
+// WithBalancer allows to choose the proxy of the pool for each connection
+// made by the FloatingProxy using the balancer, see RoundRobin, Random,
+// LeastConnections and Weighted.
+//
+// Without the balancer the FloatingProxy takes the proxy, which is
+// available in the pool for the longest time, and waits if all proxies are
+// taken by Pool.Get. The balancer chooses from all proxies of the pool,
+// including the taken ones.
+//
+// Passed to NewPool, AttachPool or NewCountryPool, the balancer becomes
+// the default of the FloatingProxies of the pool, including the ones of
+// Pool.HTTPClient, they share its state, e.g. the position of RoundRobin.
+func WithBalancer(balancer Balancer) Option {
+	fun := func(s *options) {
+		s.balancer = balancer
+	}
+
+	return optionFunc(fun)
+}
+
+// RoundRobin returns the Balancer, which chooses the proxies one by one in
+// the order of the pool.
+func RoundRobin() Balancer {
+	return &roundRobinBalancer{}
+}
+
+type roundRobinBalancer struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobinBalancer) Next(proxies []*Proxy) *Proxy {
+	return proxies[(b.next.Add(1)-1)%uint64(len(proxies))]
+}
+
+// Random returns the Balancer, which chooses the proxies randomly with
+// the same probability.
+func Random() Balancer {
+	return randomBalancer{}
+}
+
+type randomBalancer struct{}
+
+func (randomBalancer) Next(proxies []*Proxy) *Proxy {
+	return proxies[rand.IntN(len(proxies))]
+}
+
+// LeastConnections returns the Balancer, which chooses the proxy with
+// the least number of active connections, see Proxy.ActiveConns. Among
+// the proxies with the same number of connections, the proxies are chosen
+// one by one.
+func LeastConnections() Balancer {
+	return &leastConnectionsBalancer{}
+}
+
+type leastConnectionsBalancer struct {
+	next atomic.Uint64
+}
+
+func (b *leastConnectionsBalancer) Next(proxies []*Proxy) *Proxy {
+	// The search starts from the next proxy each time, so ties are
+	// resolved in the round-robin order.
+	start := int((b.next.Add(1) - 1) % uint64(len(proxies)))
+
+	best := proxies[start]
+	least := best.ActiveConns()
+
+	for i := 1; i < len(proxies) && least > 0; i++ {
+		prx := proxies[(start+i)%len(proxies)]
+
+		if active := prx.ActiveConns(); active < least {
+			best, least = prx, active
+		}
+	}
+
+	return best
+}
+
+// Weighted returns the Balancer, which chooses the proxies in proportion
+// to the weights, the i-th weight is the weight of the i-th proxy of
+// the pool. The proxies without the weight and with non-positive weights
+// have the weight of one.
+//
+// The proxies are interleaved using the smooth weighted round-robin
+// algorithm, e.g. the weights 5, 1, 1 give the order a, a, b, a, c, a, a.
+func Weighted(weights ...int) Balancer {
+	return &weightedBalancer{weights: weights}
+}
+
+type weightedBalancer struct {
+	weights []int
+
+	mu      sync.Mutex
+	current []int
+}
+
+func (b *weightedBalancer) Next(proxies []*Proxy) *Proxy {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.current) != len(proxies) {
+		b.current = make([]int, len(proxies))
+	}
+
+	best, total := 0, 0
+
+	for i := range proxies {
+		weight := b.weight(i)
+		total += weight
+		b.current[i] += weight
+
+		if b.current[i] > b.current[best] {
+			best = i
+		}
+	}
+
+	b.current[best] -= total
+
+	return proxies[best]
+}
+
+func (b *weightedBalancer) weight(i int) int {
+	if i >= len(b.weights) || b.weights[i] <= 0 {
+		return 1
+	}
+
+	return b.weights[i]
+}
+
+// ActiveConns returns the number of the connections made by DialContext
+// of the proxy, which are not closed yet, including the connections being
+// dialed.
+func (p *Proxy) ActiveConns() int {
+	return int(p.active.Load())
+}
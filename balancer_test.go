@@ -0,0 +1,365 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestProxies creates proxies that are not backed by tor.
+func newTestProxies(t *testing.T, size int) []*Proxy {
+	t.Helper()
+
+	return newTestPool(t, size).proxies
+}
+
+// lastProxyBalancer always chooses the last proxy.
+type lastProxyBalancer struct{}
+
+func (lastProxyBalancer) Next(proxies []*Proxy) *Proxy {
+	return proxies[len(proxies)-1]
+}
+
+func TestRoundRobin(t *testing.T) {
+	t.Parallel()
+	t.Run("Should choose proxies one by one", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		proxies := newTestProxies(t, 3)
+		balancer := RoundRobin()
+
+		// act
+		var got []*Proxy
+		for range 6 {
+			got = append(got, balancer.Next(proxies))
+		}
+
+		// assert
+		want := append(slices.Clone(proxies), proxies...)
+		if !slices.Equal(got, want) {
+			t.Fatal("proxies should be chosen in the order of the pool")
+		}
+	})
+}
+
+func TestRandom(t *testing.T) {
+	t.Parallel()
+	t.Run("Should choose each proxy eventually", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		proxies := newTestProxies(t, 3)
+		balancer := Random()
+		seen := make(map[*Proxy]bool)
+
+		// act
+		for range 1000 {
+			seen[balancer.Next(proxies)] = true
+		}
+
+		// assert
+		if len(seen) != len(proxies) {
+			t.Fatalf("got %d chosen proxies, want %d", len(seen), len(proxies))
+		}
+	})
+}
+
+func TestLeastConnections(t *testing.T) {
+	t.Parallel()
+	t.Run("Should choose proxy with least active connections", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		proxies := newTestProxies(t, 3)
+		proxies[0].active.Store(2)
+		proxies[1].active.Store(1)
+		proxies[2].active.Store(3)
+		balancer := LeastConnections()
+
+		// act
+		for range 3 {
+			got := balancer.Next(proxies)
+
+			// assert
+			if got != proxies[1] {
+				t.Fatal("proxy with least active connections should be chosen")
+			}
+		}
+	})
+
+	t.Run("Should choose idle proxies one by one", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		proxies := newTestProxies(t, 3)
+		balancer := LeastConnections()
+
+		// act
+		var got []*Proxy
+		for range 3 {
+			got = append(got, balancer.Next(proxies))
+		}
+
+		// assert
+		if !slices.Equal(got, proxies) {
+			t.Fatal("idle proxies should be chosen in the order of the pool")
+		}
+	})
+}
+
+func TestWeighted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		weights []int
+		want    []int
+	}{
+		{
+			name:    "Should interleave proxies smoothly",
+			weights: []int{5, 1, 1},
+			want:    []int{0, 0, 1, 0, 2, 0, 0},
+		},
+		{
+			name:    "Should use weight of one for missing and non-positive weights",
+			weights: []int{2, 0},
+			want:    []int{0, 1, 2, 0},
+		},
+		{
+			name:    "Should behave like round robin without weights",
+			weights: nil,
+			want:    []int{0, 1, 2, 0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			proxies := newTestProxies(t, 3)
+			balancer := Weighted(tt.weights...)
+
+			// act
+			var got []int
+			for range tt.want {
+				got = append(got, slices.Index(proxies, balancer.Next(proxies)))
+			}
+
+			// assert
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got order %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBalancer(t *testing.T) {
+	t.Parallel()
+
+	newTestSOCKS5Pool := func(t *testing.T, replies ...byte) (*Pool, []<-chan socks5Request) {
+		t.Helper()
+
+		pool := newFreePool(len(replies), nil, nil)
+		requests := make([]<-chan socks5Request, 0, len(replies))
+
+		for _, reply := range replies {
+			addr, reqs := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", reply)
+
+			prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pool.add(prx)
+			requests = append(requests, reqs)
+		}
+
+		return pool, requests
+	}
+
+	t.Run("Should spread connections across proxies", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool, requests := newTestSOCKS5Pool(t, 0x00, 0x00, 0x00)
+		floating := NewFloatingProxy(pool, WithBalancer(RoundRobin()))
+
+		// act
+		for range 6 {
+			conn, err := floating.DialContext(context.Background(), "tcp", "example.com:80")
+			if err != nil {
+				t.Fatal("should not get an error:", err)
+			}
+
+			_ = conn.Close()
+		}
+
+		// assert
+		for i, reqs := range requests {
+			if got := len(reqs); got != 2 {
+				t.Fatalf("got %d connections of proxy %d, want 2", got, i)
+			}
+		}
+	})
+
+	t.Run("Should count active connections of proxy", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool, _ := newTestSOCKS5Pool(t, 0x00, 0x00)
+		floating := NewFloatingProxy(pool, WithBalancer(LeastConnections()))
+
+		// act
+		first, err := floating.DialContext(context.Background(), "tcp", "example.com:80")
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		second, err := floating.DialContext(context.Background(), "tcp", "example.com:80")
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		// assert
+		for _, prx := range pool.proxies {
+			if got := prx.ActiveConns(); got != 1 {
+				t.Fatalf("got %d active connections, want 1", got)
+			}
+		}
+
+		_ = first.Close()
+		_ = first.Close()
+		_ = second.Close()
+
+		for _, prx := range pool.proxies {
+			if got := prx.ActiveConns(); got != 0 {
+				t.Fatalf("got %d active connections after close, want 0", got)
+			}
+		}
+	})
+
+	t.Run("Should retry with other proxy", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool, requests := newTestSOCKS5Pool(t, 0x04, 0x00)
+		floating := NewFloatingProxy(pool,
+			WithBalancer(Weighted(100, 1)),
+			WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}),
+		)
+
+		// act
+		conn, err := floating.DialContext(context.Background(), "tcp", "example.com:80")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+		defer conn.Close()
+
+		if len(requests[0]) != 1 || len(requests[1]) != 1 {
+			t.Fatalf("got %d and %d attempts, want 1 and 1", len(requests[0]), len(requests[1]))
+		}
+	})
+
+	retries := []struct {
+		name     string
+		balancer Balancer
+		replies  []byte
+		want     []int
+	}{
+		{
+			name:     "Should keep weights of proxies on retry with weighted balancer",
+			balancer: Weighted(10, 1, 5),
+			replies:  []byte{0x04, 0x00, 0x00},
+			want:     []int{1, 0, 1},
+		},
+		{
+			name:     "Should skip failed proxy on retry with weighted balancer",
+			balancer: Weighted(1, 1, 10),
+			replies:  []byte{0x00, 0x00, 0x04},
+			want:     []int{1, 0, 1},
+		},
+		{
+			name:     "Should keep order of proxies on retry with round robin balancer",
+			balancer: RoundRobin(),
+			replies:  []byte{0x04, 0x00, 0x00},
+			want:     []int{1, 1, 0},
+		},
+	}
+
+	for _, tt := range retries {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			pool, requests := newTestSOCKS5Pool(t, tt.replies...)
+			floating := NewFloatingProxy(pool,
+				WithBalancer(tt.balancer),
+				WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}),
+			)
+
+			// act
+			conn, err := floating.DialContext(context.Background(), "tcp", "example.com:80")
+			// assert
+			if err != nil {
+				t.Fatal("should not get an error:", err)
+			}
+			defer conn.Close()
+
+			got := make([]int, 0, len(requests))
+			for _, reqs := range requests {
+				got = append(got, len(reqs))
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got attempts per proxy %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Should use balancer of pool by default", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		first, firstRequests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+		last, lastRequests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+
+		pool, err := AttachPool(context.Background(), []string{first.address, last.address}, "", nil,
+			WithBalancer(lastProxyBalancer{}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			_ = pool.Close()
+		})
+
+		// act
+		for range 3 {
+			resp, err := pool.HTTPClient().Get("http://example.com/")
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+		}
+
+		// assert
+		if len(firstRequests) != 0 || len(lastRequests) != 3 {
+			t.Fatalf("got %d and %d connections, want 0 and 3", len(firstRequests), len(lastRequests))
+		}
+	})
+
+	t.Run("Should return ErrPoolClosed after close", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool, _ := newTestSOCKS5Pool(t, 0x00)
+		floating := NewFloatingProxy(pool, WithBalancer(Random()))
+		_ = pool.Close()
+
+		// act
+		_, err := floating.DialContext(context.Background(), "tcp", "example.com:80")
+
+		// assert
+		if !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("got error %v, want %v", err, ErrPoolClosed)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BootstrapStatus describes the progress of the tor demon bootstrapping,
+// the fields match the fields of the BOOTSTRAP status event of the tor
+// control protocol.
+type BootstrapStatus struct {
+	// Progress is the percentage of the bootstrapping completion.
+	Progress int
+	// Tag is the short name of the bootstrap phase, e.g. "conn_done".
+	Tag string
+	// Summary is the human-readable description of the bootstrap phase.
+	Summary string
+	// Warning is the human-readable description of the problem which
+	// stalls bootstrapping, it is empty if there is no problem.
+	Warning string
+	// Reason is the machine-readable description of the problem, e.g.
+	// "CONNECTREFUSED", it is empty if there is no problem.
+	Reason string
+}
+
+var (
+	// Matches lines like:
+	//	Bootstrapped 14% (handshake): Handshaking with a relay
+	bootstrapRegexp = regexp.MustCompile(`Bootstrapped (\d+)% \(([^)]*)\): (.*)$`)
+
+	// Matches lines like:
+	//	Problem bootstrapping. Stuck at 14% (handshake): Handshaking with
+	//	a relay. (Connection refused; CONNECTREFUSED; count 1; ...)
+	bootstrapProblemRegexp = regexp.MustCompile(
+		`Problem bootstrapping\. Stuck at (\d+)% \(([^)]*)\): (.*?)\. \((.*)\)$`,
+	)
+)
+
+// WithBootstrapProgress allows to receive the progress of the tor demon
+// bootstrapping during NewProxy and NewPool.
+//
+// The function is called sequentially from a separate goroutine, it
+// should not block for a long time.
+func WithBootstrapProgress(fun func(BootstrapStatus)) Option {
+	option := func(s *options) {
+		s.bootstrapProgress = fun
+	}
+
+	return optionFunc(option)
+}
+
+// parseBootstrapStatus parses the bootstrap status from the line of
+// the tor log.
+func parseBootstrapStatus(line string) (status BootstrapStatus, ok bool) {
+	if match := bootstrapRegexp.FindStringSubmatch(line); match != nil {
+		status.Progress, _ = strconv.Atoi(match[1])
+		status.Tag = match[2]
+		status.Summary = match[3]
+
+		return status, true
+	}
+
+	if match := bootstrapProblemRegexp.FindStringSubmatch(line); match != nil {
+		status.Progress, _ = strconv.Atoi(match[1])
+		status.Tag = match[2]
+		status.Summary = match[3]
+
+		details := strings.Split(match[4], "; ")
+		status.Warning = details[0]
+
+		if len(details) > 1 {
+			status.Reason = details[1]
+		}
+
+		return status, true
+	}
+
+	return BootstrapStatus{}, false
+}
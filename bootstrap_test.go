@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"testing"
+)
+
+func Test_parseBootstrapStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		want   BootstrapStatus
+		wantOk bool
+	}{
+		{
+			name: "Should parse progress line",
+			line: "Oct 18 08:36:59.000 [notice] Bootstrapped 14% (handshake): Handshaking with a relay",
+			want: BootstrapStatus{
+				Progress: 14,
+				Tag:      "handshake",
+				Summary:  "Handshaking with a relay",
+			},
+			wantOk: true,
+		},
+		{
+			name: "Should parse completion line",
+			line: "Oct 18 08:36:59.000 [notice] Bootstrapped 100% (done): Done",
+			want: BootstrapStatus{
+				Progress: 100,
+				Tag:      "done",
+				Summary:  "Done",
+			},
+			wantOk: true,
+		},
+		{
+			name: "Should parse problem line",
+			line: "Oct 18 08:36:59.000 [warn] Problem bootstrapping. Stuck at 5% (conn): " +
+				"Connecting to a relay. (Connection refused; CONNECTREFUSED; count 1; " +
+				"recommendation warn; host 0000 at 192.0.2.1:443)",
+			want: BootstrapStatus{
+				Progress: 5,
+				Tag:      "conn",
+				Summary:  "Connecting to a relay",
+				Warning:  "Connection refused",
+				Reason:   "CONNECTREFUSED",
+			},
+			wantOk: true,
+		},
+		{
+			name:   "Should skip unrelated line",
+			line:   "Oct 18 08:36:59.000 [notice] Opening Socks listener on 127.0.0.1:9050",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseBootstrapStatus(tt.line)
+			if ok != tt.wantOk {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOk)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	transportNameRegexp     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	bridgeFingerprintRegexp = regexp.MustCompile(`^[0-9A-Fa-f]{40}$`)
+)
+
+// bridgeRequiredParams are the parameters of the bridge line required by
+// the known pluggable transports.
+var bridgeRequiredParams = map[string][]string{
+	"obfs4":     {"cert", "iat-mode"},
+	"snowflake": {"url"},
+	"webtunnel": {"url"},
+	"meek":      {"url"},
+	"meek_lite": {"url"},
+}
+
+// A Bridge is a bridge relay, which is used to connect to the tor network
+// where the access to it is blocked.
+type Bridge struct {
+	// Transport is the name of the pluggable transport, e.g. "obfs4",
+	// it is empty for vanilla bridges.
+	Transport string
+	// Addr is the address of the bridge.
+	Addr netip.AddrPort
+	// Fingerprint is the identity fingerprint of the bridge, it is
+	// optional.
+	Fingerprint string
+	// Params are the arguments of the pluggable transport, e.g. "cert"
+	// and "iat-mode" of obfs4.
+	Params map[string]string
+}
+
+// A BridgeError describes the problem of the bridge line.
+type BridgeError struct {
+	// Line is the bridge line.
+	Line string
+	// Field is the name of the invalid field: "line", "transport",
+	// "address", "fingerprint", "parameter" or the name of the parameter
+	// of the transport.
+	Field string
+	// Reason is the description of the problem.
+	Reason string
+}
+
+func (e *BridgeError) Error() string {
+	const format = "tornado: invalid %s of bridge line %q: %s"
+	return fmt.Sprintf(format, e.Field, e.Line, e.Reason)
+}
+
+// ParseBridge parses the bridge line in the format of the Bridge option of
+// torrc, e.g. "obfs4 192.0.2.1:443 <fingerprint> cert=... iat-mode=0",
+// as distributed by https://bridges.torproject.org. The "Bridge" prefix is
+// optional. The error is *BridgeError.
+//
+// The vanilla, obfs4, snowflake, webtunnel and meek bridges are validated
+// according to their transports, other transports are parsed as is.
+func ParseBridge(line string) (Bridge, error) {
+	fields := strings.Fields(line)
+	if len(fields) > 0 && fields[0] == "Bridge" {
+		fields = fields[1:]
+	}
+
+	fail := func(field, format string, args ...any) (Bridge, error) {
+		return Bridge{}, &BridgeError{Line: line, Field: field, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if strings.ContainsAny(strings.TrimSpace(line), "\r\n") {
+		return fail("line", "must not contain line breaks")
+	}
+
+	if len(fields) == 0 {
+		return fail("address", "missing")
+	}
+
+	var bridge Bridge
+
+	if _, err := netip.ParseAddrPort(fields[0]); err != nil {
+		if !transportNameRegexp.MatchString(fields[0]) {
+			return fail("transport", "%q is neither a transport name nor an address", fields[0])
+		}
+
+		bridge.Transport = fields[0]
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return fail("address", "missing")
+	}
+
+	addr, err := netip.ParseAddrPort(fields[0])
+	if err != nil {
+		return fail("address", "%q is not an IP address with port", fields[0])
+	}
+
+	bridge.Addr = addr
+	fields = fields[1:]
+
+	if len(fields) > 0 && !strings.Contains(fields[0], "=") {
+		if !bridgeFingerprintRegexp.MatchString(fields[0]) {
+			return fail("fingerprint", "%q is not 40 hexadecimal digits", fields[0])
+		}
+
+		bridge.Fingerprint = strings.ToUpper(fields[0])
+		fields = fields[1:]
+	}
+
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return fail("parameter", "%q is not in the key=value format", field)
+		}
+
+		if bridge.Transport == "" {
+			return fail(key, "vanilla bridges have no parameters")
+		}
+
+		if bridge.Params == nil {
+			bridge.Params = make(map[string]string)
+		}
+
+		bridge.Params[key] = value
+	}
+
+	for _, key := range bridgeRequiredParams[bridge.Transport] {
+		if _, ok := bridge.Params[key]; !ok {
+			return fail(key, "required by the %s transport", bridge.Transport)
+		}
+	}
+
+	if mode, ok := bridge.Params["iat-mode"]; ok && bridge.Transport == "obfs4" {
+		if mode != "0" && mode != "1" && mode != "2" {
+			return fail("iat-mode", "%q is not 0, 1 or 2", mode)
+		}
+	}
+
+	if url, ok := bridge.Params["url"]; ok {
+		if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			return fail("url", "%q is not an HTTP URL", url)
+		}
+	}
+
+	return bridge, nil
+}
+
+// String returns the bridge line in the format of the Bridge option of
+// torrc without the "Bridge" prefix.
+func (b Bridge) String() string {
+	var fields []string
+
+	if b.Transport != "" {
+		fields = append(fields, b.Transport)
+	}
+
+	fields = append(fields, b.Addr.String())
+
+	if b.Fingerprint != "" {
+		fields = append(fields, b.Fingerprint)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(b.Params)) {
+		fields = append(fields, key+"="+b.Params[key])
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// WithBridges allows to connect to the tor network through the bridges,
+// the lines are parsed by ParseBridge, invalid lines are reported by
+// NewProxy and NewPool.
+//
+// The option adds the lines to Config.Bridges and enables
+// Config.UseBridges, so WithConfig used after it replaces them. Bridges
+// using a pluggable transport require it to be configured, see
+// WithPluggableTransport.
+func WithBridges(lines ...string) Option {
+	fun := func(s *options) {
+		bridges := make([]string, 0, len(lines))
+
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			line = strings.TrimPrefix(line, "Bridge ")
+
+			bridges = append(bridges, line)
+		}
+
+		s.config.Bridges = slices.Concat(s.config.Bridges, bridges)
+
+		s.config.UseBridges = true
+	}
+
+	return optionFunc(fun)
+}
+
+// WithPluggableTransport allows to use the pluggable transport executable
+// for bridges of the transport, e.g.
+//
+//	WithPluggableTransport("obfs4", "/usr/bin/lyrebird")
+//
+// Executables supporting several transports can be configured once using
+// a comma-separated list of names, e.g. "obfs4,webtunnel,meek_lite".
+// The path and the arguments must not contain spaces.
+//
+// The option adds the transport to Config.ClientTransportPlugins.
+func WithPluggableTransport(name, execPath string, args ...string) Option {
+	fun := func(s *options) {
+		line := strings.Join(append([]string{name, "exec", execPath}, args...), " ")
+		s.config.ClientTransportPlugins = slices.Concat(s.config.ClientTransportPlugins, []string{line})
+	}
+
+	return optionFunc(fun)
+}
+
+// validateTransportPlugin validates the line of the ClientTransportPlugin
+// option in the "<names> exec <path> [args]" or "<names> socks5 <address>"
+// format.
+func validateTransportPlugin(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || strings.ContainsAny(line, "\r\n") {
+		const format = "%q must have the transport names, the exec or proxy type and the path or address"
+		return fmt.Errorf(format, line)
+	}
+
+	for _, name := range strings.Split(fields[0], ",") {
+		if !transportNameRegexp.MatchString(name) {
+			const format = "%q is not a transport name"
+			return fmt.Errorf(format, name)
+		}
+	}
+
+	switch fields[1] {
+	case "exec":
+	case "socks4", "socks5":
+		if len(fields) != 3 {
+			const format = "%q must have only the address of the proxy"
+			return fmt.Errorf(format, line)
+		}
+	default:
+		const format = "%q is not exec, socks4 or socks5"
+		return fmt.Errorf(format, fields[1])
+	}
+
+	return nil
+}
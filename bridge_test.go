@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const testBridgeFingerprint = "0123456789ABCDEF0123456789ABCDEF01234567"
+
+func TestParseBridge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		line      string
+		want      string
+		wantField string
+	}{
+		{
+			name: "Should parse vanilla bridge",
+			line: "192.0.2.1:443 " + testBridgeFingerprint,
+			want: "192.0.2.1:443 " + testBridgeFingerprint,
+		},
+		{
+			name: "Should parse obfs4 bridge with prefix",
+			line: "Bridge obfs4 192.0.2.2:443 " + testBridgeFingerprint + " cert=c2VjcmV0 iat-mode=0",
+			want: "obfs4 192.0.2.2:443 " + testBridgeFingerprint + " cert=c2VjcmV0 iat-mode=0",
+		},
+		{
+			name: "Should parse snowflake bridge",
+			line: "snowflake 192.0.2.3:80 " + testBridgeFingerprint + " url=https://snowflake.example/ ice=stun:stun.example:3478",
+			want: "snowflake 192.0.2.3:80 " + testBridgeFingerprint + " ice=stun:stun.example:3478 url=https://snowflake.example/",
+		},
+		{
+			name: "Should parse webtunnel bridge with IPv6 address",
+			line: "webtunnel [2001:db8::1]:443 " + testBridgeFingerprint + " url=https://webtunnel.example/path ver=0.0.1",
+			want: "webtunnel [2001:db8::1]:443 " + testBridgeFingerprint + " url=https://webtunnel.example/path ver=0.0.1",
+		},
+		{
+			name: "Should parse meek bridge",
+			line: "meek_lite 192.0.2.18:80 " + testBridgeFingerprint + " url=https://meek.example/ front=www.example.com",
+			want: "meek_lite 192.0.2.18:80 " + testBridgeFingerprint + " front=www.example.com url=https://meek.example/",
+		},
+		{
+			name:      "Should report invalid address",
+			line:      "obfs4 bridge.example:443 " + testBridgeFingerprint,
+			wantField: "address",
+		},
+		{
+			name:      "Should report invalid fingerprint",
+			line:      "192.0.2.1:443 0123",
+			wantField: "fingerprint",
+		},
+		{
+			name:      "Should report missing parameter",
+			line:      "obfs4 192.0.2.2:443 " + testBridgeFingerprint + " cert=c2VjcmV0",
+			wantField: "iat-mode",
+		},
+		{
+			name:      "Should report invalid parameter value",
+			line:      "obfs4 192.0.2.2:443 " + testBridgeFingerprint + " cert=c2VjcmV0 iat-mode=3",
+			wantField: "iat-mode",
+		},
+		{
+			name:      "Should report invalid transport",
+			line:      "obfs-4 192.0.2.2:443",
+			wantField: "transport",
+		},
+		{
+			name:      "Should report line break",
+			line:      "192.0.2.1:443\nExitNodes {us}",
+			wantField: "line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bridge, err := ParseBridge(tt.line)
+
+			if tt.wantField != "" {
+				var bridgeErr *BridgeError
+				if !errors.As(err, &bridgeErr) {
+					t.Fatalf("BridgeError error was expected, but got another one: %v", err)
+				}
+
+				if bridgeErr.Field != tt.wantField {
+					t.Fatalf("got field %q, want %q", bridgeErr.Field, tt.wantField)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal("should not get an error:", err)
+			}
+
+			if got := bridge.String(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBridges(t *testing.T) {
+	t.Parallel()
+	t.Run("Should render bridges and transport plugin", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var state options
+
+		// act
+		WithBridges("Bridge obfs4 192.0.2.2:443 " + testBridgeFingerprint + " cert=c2VjcmV0 iat-mode=0").apply(&state)
+		WithPluggableTransport("obfs4,webtunnel", "/usr/bin/lyrebird", "-enableLogging").apply(&state)
+
+		// assert
+		if err := state.config.Validate(); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		want := []string{
+			"UseBridges 1",
+			"Bridge obfs4 192.0.2.2:443 " + testBridgeFingerprint + " cert=c2VjcmV0 iat-mode=0",
+			"ClientTransportPlugin obfs4,webtunnel exec /usr/bin/lyrebird -enableLogging",
+		}
+
+		if got := state.config.torrcLines(); !slices.Equal(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Should report invalid bridge on validation", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var state options
+
+		WithBridges("obfs4 192.0.2.2:443").apply(&state)
+
+		// act
+		err := state.config.Validate()
+
+		// assert
+		var bridgeErr *BridgeError
+		if !errors.As(err, &bridgeErr) {
+			t.Fatalf("BridgeError error was expected, but got another one: %v", err)
+		}
+	})
+
+	t.Run("Should report invalid transport plugin on validation", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var state options
+
+		WithPluggableTransport("obfs4", "").apply(&state)
+
+		// act
+		err := state.config.Validate()
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
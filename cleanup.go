@@ -0,0 +1,64 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RemoveStaleDataDirectories removes the temporary data directories of
+// tor demons left by tornado in the os.TempDir by processes that are no
+// longer running, for example, after a crash or kill of the process.
+//
+// It is intended to be called once at the startup of the program.
+func RemoveStaleDataDirectories() error {
+	matches, err := filepath.Glob(filepath.Join(os.TempDir(), "tornado.*.*"))
+	if err != nil {
+		const format = "tornado: cannot find data directories: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	var errs []error
+
+	for _, path := range matches {
+		if !isStaleDataDirectory(path) {
+			continue
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			const format = "tornado: cannot remove stale data directory: %v"
+			errs = append(errs, fmt.Errorf(format, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isStaleDataDirectory reports whether the path is the data directory
+// created by the process that is no longer running.
+func isStaleDataDirectory(path string) bool {
+	// The format of the name is "tornado.<pid>.<random>".
+	parts := strings.SplitN(filepath.Base(path), ".", 3)
+	if len(parts) != 3 {
+		return false
+	}
+
+	pid, err := strconv.Atoi(parts[1])
+	if err != nil || pid <= 0 || pid == os.Getpid() {
+		return false
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+
+	return !processExists(pid)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveStaleDataDirectories(t *testing.T) {
+	t.Run("Should remove only directories of processes that are not running", func(t *testing.T) {
+		// arrange
+		tmp := t.TempDir()
+		t.Setenv("TMPDIR", tmp)
+
+		cmd := exec.Command("true")
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		stale := filepath.Join(tmp, fmt.Sprintf("tornado.%d.1", cmd.Process.Pid))
+		alive := filepath.Join(tmp, fmt.Sprintf("tornado.%d.1", os.Getpid()))
+
+		for _, dir := range []string{stale, alive} {
+			if err := os.Mkdir(dir, 0o700); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// act
+		err := RemoveStaleDataDirectories()
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if _, err := os.Stat(stale); !os.IsNotExist(err) {
+			t.Fatalf("stale directory %q should be removed, got %v", stale, err)
+		}
+
+		if _, err := os.Stat(alive); err != nil {
+			t.Fatalf("directory %q of running process should be kept: %v", alive, err)
+		}
+	})
+}
+
+func TestNewProxy_removesDataDirectoryOnFailedLaunch(t *testing.T) {
+	// arrange
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	path := filepath.Join(t.TempDir(), "tor")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 1\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err := NewProxy(context.Background(), WithTorBinary(path))
+
+	// assert
+	if err == nil {
+		t.Fatal("an error was expected")
+	}
+
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("data directory should be removed, got %v", entries)
+	}
+}
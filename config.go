@@ -0,0 +1,273 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Matches the identity fingerprint of a node with the optional "$"
+	// prefix and the optional nickname, e.g. "$ABCD...=nickname".
+	nodeFingerprintRegexp = regexp.MustCompile(`^\$?[0-9A-Fa-f]{40}(?:[=~][A-Za-z0-9]{1,19})?$`)
+	nodeNicknameRegexp    = regexp.MustCompile(`^[A-Za-z0-9]{1,19}$`)
+	nodeCountryRegexp     = regexp.MustCompile(`^\{[A-Za-z]{2}\}$`)
+)
+
+// Config is the typed configuration of the common client options of tor,
+// the zero value of a field means the default value of tor.
+//
+// See https://2019.www.torproject.org/docs/tor-manual.html.en for
+// a description of the options.
+type Config struct {
+	// ExitNodes is the list of nodes to use as the last hop of circuits,
+	// each node is an identity fingerprint, a nickname, a country code in
+	// curly braces like "{us}", or an address pattern like "192.0.2.0/24".
+	ExitNodes []string
+	// ExcludeNodes is the list of nodes to never use when building
+	// circuits, in the format of ExitNodes.
+	ExcludeNodes []string
+	// ExcludeExitNodes is the list of nodes to never use as the last hop
+	// of circuits, in the format of ExitNodes.
+	ExcludeExitNodes []string
+	// StrictNodes makes tor treat ExcludeNodes as a requirement, instead
+	// of a preference, which tor may ignore when it can't build circuits.
+	StrictNodes bool
+
+	// UseBridges makes tor connect to the network through the Bridges.
+	UseBridges bool
+	// Bridges is the list of bridge lines, e.g.
+	// "obfs4 192.0.2.1:443 <fingerprint> cert=... iat-mode=0", see
+	// ParseBridge.
+	Bridges []string
+	// ClientTransportPlugins is the list of pluggable transports, e.g.
+	// "obfs4 exec /usr/bin/lyrebird".
+	ClientTransportPlugins []string
+
+	// CircuitBuildTimeout is the time to build a circuit, setting it
+	// disables the adaptive circuit build timeout of tor.
+	CircuitBuildTimeout time.Duration
+	// MaxCircuitDirtiness is the time to reuse a circuit for new streams.
+	MaxCircuitDirtiness time.Duration
+	// NewCircuitPeriod is the time to consider building a new circuit.
+	NewCircuitPeriod time.Duration
+
+	// ConnectionPadding enables or disables padding of connections to
+	// relays, nil means that tor decides it automatically.
+	ConnectionPadding *bool
+}
+
+// Validate reports the first problem of the configuration, which tor would
+// fail to launch with.
+func (c Config) Validate() error {
+	nodeLists := []struct {
+		name  string
+		nodes []string
+	}{
+		{name: "ExitNodes", nodes: c.ExitNodes},
+		{name: "ExcludeNodes", nodes: c.ExcludeNodes},
+		{name: "ExcludeExitNodes", nodes: c.ExcludeExitNodes},
+	}
+
+	for _, list := range nodeLists {
+		for _, node := range list.nodes {
+			if err := validateNode(node); err != nil {
+				const format = "tornado: invalid node in %s: %w"
+				return fmt.Errorf(format, list.name, err)
+			}
+		}
+	}
+
+	for _, line := range c.Bridges {
+		if _, err := ParseBridge(line); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range c.ClientTransportPlugins {
+		if err := validateTransportPlugin(line); err != nil {
+			const format = "tornado: invalid ClientTransportPlugin: %w"
+			return fmt.Errorf(format, err)
+		}
+	}
+
+	if c.UseBridges && len(c.Bridges) == 0 {
+		return errors.New("tornado: UseBridges requires at least one bridge")
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{name: "CircuitBuildTimeout", value: c.CircuitBuildTimeout},
+		{name: "MaxCircuitDirtiness", value: c.MaxCircuitDirtiness},
+		{name: "NewCircuitPeriod", value: c.NewCircuitPeriod},
+	}
+
+	for _, duration := range durations {
+		if duration.value < 0 || duration.value%time.Second != 0 {
+			const format = "tornado: %s must be a whole non-negative number of seconds, got %v"
+			return fmt.Errorf(format, duration.name, duration.value)
+		}
+	}
+
+	return nil
+}
+
+// validateNode validates the node in the format of ExitNodes.
+func validateNode(node string) error {
+	if nodeCountryRegexp.MatchString(node) {
+		return validateCountryCode(node[1 : len(node)-1])
+	}
+
+	if nodeFingerprintRegexp.MatchString(node) || nodeNicknameRegexp.MatchString(node) {
+		return nil
+	}
+
+	if _, err := netip.ParsePrefix(node); err == nil {
+		return nil
+	}
+
+	if _, err := netip.ParseAddr(node); err == nil {
+		return nil
+	}
+
+	const format = "%q is not a fingerprint, nickname, country code or address"
+
+	return fmt.Errorf(format, node)
+}
+
+// torrcLines returns the lines of torrc for the configuration.
+func (c Config) torrcLines() []string {
+	var lines []string
+
+	nodeLists := []struct {
+		name  string
+		nodes []string
+	}{
+		{name: "ExitNodes", nodes: c.ExitNodes},
+		{name: "ExcludeNodes", nodes: c.ExcludeNodes},
+		{name: "ExcludeExitNodes", nodes: c.ExcludeExitNodes},
+	}
+
+	for _, list := range nodeLists {
+		if len(list.nodes) > 0 {
+			lines = append(lines, list.name+" "+strings.Join(list.nodes, ","))
+		}
+	}
+
+	if c.StrictNodes {
+		lines = append(lines, "StrictNodes 1")
+	}
+
+	if c.UseBridges {
+		lines = append(lines, "UseBridges 1")
+	}
+
+	for _, bridge := range c.Bridges {
+		lines = append(lines, "Bridge "+bridge)
+	}
+
+	for _, plugin := range c.ClientTransportPlugins {
+		lines = append(lines, "ClientTransportPlugin "+plugin)
+	}
+
+	if c.CircuitBuildTimeout > 0 {
+		lines = append(lines,
+			"LearnCircuitBuildTimeout 0",
+			"CircuitBuildTimeout "+torrcSeconds(c.CircuitBuildTimeout),
+		)
+	}
+
+	if c.MaxCircuitDirtiness > 0 {
+		lines = append(lines, "MaxCircuitDirtiness "+torrcSeconds(c.MaxCircuitDirtiness))
+	}
+
+	if c.NewCircuitPeriod > 0 {
+		lines = append(lines, "NewCircuitPeriod "+torrcSeconds(c.NewCircuitPeriod))
+	}
+
+	if c.ConnectionPadding != nil {
+		padding := "0"
+		if *c.ConnectionPadding {
+			padding = "1"
+		}
+
+		lines = append(lines, "ConnectionPadding "+padding)
+	}
+
+	return lines
+}
+
+func torrcSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10) + " seconds"
+}
+
+// WithConfig allows to configure the tor demon using the typed
+// configuration, it replaces the configuration set by the previous
+// WithConfig. The configuration is validated before the launch of the tor
+// demon, options added by WithTorrcOption take precedence over it.
+func WithConfig(cfg Config) Option {
+	fun := func(s *options) {
+		s.config = cfg.clone()
+	}
+
+	return optionFunc(fun)
+}
+
+// clone returns the copy of the configuration, which doesn't share
+// the backing arrays of the lists with the original, so options appending
+// to the lists don't modify the configuration of the caller.
+func (c Config) clone() Config {
+	c.ExitNodes = slices.Clone(c.ExitNodes)
+	c.ExcludeNodes = slices.Clone(c.ExcludeNodes)
+	c.ExcludeExitNodes = slices.Clone(c.ExcludeExitNodes)
+	c.Bridges = slices.Clone(c.Bridges)
+	c.ClientTransportPlugins = slices.Clone(c.ClientTransportPlugins)
+
+	return c
+}
+
+// WithVerifyConfig makes NewProxy and NewPool run "tor --verify-config"
+// for the torrc before the launch of the tor demon, so mistakes in
+// the configuration are reported without waiting for the bootstrapping.
+func WithVerifyConfig() Option {
+	fun := func(s *options) {
+		s.verifyConfig = true
+	}
+
+	return optionFunc(fun)
+}
+
+// verifyTorrc runs "tor --verify-config" for the torrc, if it is enabled
+// by WithVerifyConfig.
+func verifyTorrc(ctx context.Context, trc torrc, state options) error {
+	if !state.verifyConfig {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, state.torBinaryPath(), "--verify-config", "-f", trc.filename)
+	cmd.Dir = trc.dataDirectory
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		const format = "tornado: tor rejected the configuration: %v" +
+			"\n\n# Torrc file:\n%s" +
+			"\n\n# Verify log:\n%s"
+
+		return fmt.Errorf(format, err, trc.torrc, output)
+	}
+
+	return nil
+}
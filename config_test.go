@@ -0,0 +1,212 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{
+			name: "Should accept all kinds of nodes",
+			config: Config{
+				ExitNodes: []string{
+					"{us}",
+					"$0123456789ABCDEF0123456789ABCDEF01234567~nickname",
+					"nickname",
+					"192.0.2.0/24",
+					"2001:db8::1",
+				},
+			},
+		},
+		{
+			name:    "Should reject malformed node",
+			config:  Config{ExcludeNodes: []string{"{us},{de}"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should reject line with line break",
+			config:  Config{Bridges: []string{"192.0.2.1:443\nExitNodes {us}"}},
+			wantErr: true,
+		},
+		{
+			name:    "Should reject bridges usage without bridges",
+			config:  Config{UseBridges: true},
+			wantErr: true,
+		},
+		{
+			name:    "Should reject fractional seconds",
+			config:  Config{MaxCircuitDirtiness: 1500 * time.Millisecond},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithConfig(t *testing.T) {
+	t.Parallel()
+	t.Run("Should render options in torrc", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		padding := false
+		config := Config{
+			ExitNodes:           []string{"{us}", "{de}"},
+			StrictNodes:         true,
+			UseBridges:          true,
+			Bridges:             []string{"192.0.2.1:443"},
+			CircuitBuildTimeout: 30 * time.Second,
+			MaxCircuitDirtiness: 10 * time.Minute,
+			ConnectionPadding:   &padding,
+		}
+
+		var state options
+
+		WithConfig(config).apply(&state)
+		state.numberOfProxy = 1
+
+		// act
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = trc.cleanup()
+		})
+
+		// assert
+		want := []string{
+			"ExitNodes {us},{de}",
+			"StrictNodes 1",
+			"UseBridges 1",
+			"Bridge 192.0.2.1:443",
+			"LearnCircuitBuildTimeout 0",
+			"CircuitBuildTimeout 30 seconds",
+			"MaxCircuitDirtiness 600 seconds",
+			"ConnectionPadding 0",
+		}
+
+		lines := strings.Split(trc.torrc, "\n")
+
+		for _, line := range want {
+			if !slices.Contains(lines, line) {
+				t.Fatalf("torrc does not contain %q:\n%s", line, trc.torrc)
+			}
+		}
+	})
+
+	t.Run("Should reject invalid config before creating torrc", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		state := options{numberOfProxy: 1}
+
+		WithConfig(Config{ExitNodes: []string{"us"}, UseBridges: true}).apply(&state)
+
+		// act
+		_, err := newTorrcFromState(state)
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+
+	t.Run("Should not share lists with config of caller", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		config := Config{
+			ExitNodes:              make([]string, 0, 1),
+			ExcludeExitNodes:       make([]string, 0, 1),
+			Bridges:                make([]string, 0, 1),
+			ClientTransportPlugins: make([]string, 0, 1),
+		}
+
+		ops := []Option{
+			WithConfig(config),
+			WithExitCountries("us"),
+			WithExcludeExitCountries("ru"),
+			WithBridges("192.0.2.1:443"),
+			WithPluggableTransport("obfs4", "/usr/bin/lyrebird"),
+		}
+
+		var first, second options
+
+		// act
+		for _, option := range ops {
+			option.apply(&first)
+		}
+
+		for _, option := range ops {
+			option.apply(&second)
+		}
+
+		second.config.ExitNodes[0] = "{de}"
+		second.config.ExcludeExitNodes[0] = "{by}"
+		second.config.Bridges[0] = "192.0.2.2:443"
+		second.config.ClientTransportPlugins[0] = "snowflake exec /usr/bin/snowflake-client"
+
+		// assert
+		want := []string{
+			"ExitNodes {us}",
+			"ExcludeExitNodes {ru}",
+			"StrictNodes 1",
+			"UseBridges 1",
+			"Bridge 192.0.2.1:443",
+			"ClientTransportPlugin obfs4 exec /usr/bin/lyrebird",
+		}
+
+		if got := first.config.torrcLines(); !slices.Equal(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestWithVerifyConfig(t *testing.T) {
+	t.Parallel()
+	t.Run("Should return output of tor on rejected config", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		binary := filepath.Join(t.TempDir(), "tor")
+		script := "#!/bin/sh\necho 'Unknown option ExitNode. Failing.'\nexit 1\n"
+
+		if err := os.WriteFile(binary, []byte(script), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		state := options{torBinary: binary}
+		WithVerifyConfig().apply(&state)
+
+		// act
+		err := verifyTorrc(context.Background(), torrc{dataDirectory: t.TempDir()}, state)
+
+		// assert
+		if err == nil || !strings.Contains(err.Error(), "Unknown option ExitNode") {
+			t.Fatalf("an error with output of tor was expected, but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// signalRegexp matches the keywords of the SIGNAL command.
+var signalRegexp = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// ErrNoController is returned when an operation requires a connection to
+// the tor control port, but it is not available.
+var ErrNoController = errors.New("tornado: control connection is not available")
+
+// A Controller is a client of the tor control protocol connected to the
+// control port of a tor demon.
+//
+// See https://spec.torproject.org/control-spec for a description of
+// the protocol.
+//
+// Controller is safe for concurrent use by multiple goroutines.
+type Controller struct {
+	// mu guards the connection, which is replaced when the tor demon is
+	// restarted by the supervisor.
+	mu     sync.Mutex
+	conn   *controlConn
+	closed bool
+
+	closeOnce sync.Once
+}
+
+// controlConn is a single connection to the control port.
+type controlConn struct {
+	conn net.Conn
+	text *textproto.Conn
+
+	// mu guards writes to the connection and the queue of pending
+	// commands, so that the replies are matched in the order in which
+	// the commands were sent.
+	mu      sync.Mutex
+	pending []chan controlReply
+
+	done chan struct{}
+	err  error
+}
+
+type controlReply struct {
+	code  int
+	lines []string
+}
+
+func (r controlReply) String() string {
+	return strconv.Itoa(r.code) + " " + strings.Join(r.lines, "\n")
+}
+
+// A ControlAuth is a method of authentication to the control port.
+type ControlAuth interface {
+	authenticate(ctx context.Context, ctrl *Controller) error
+}
+
+type controlAuthFunc func(ctx context.Context, ctrl *Controller) error
+
+func (f controlAuthFunc) authenticate(ctx context.Context, ctrl *Controller) error {
+	return f(ctx, ctrl)
+}
+
+// CookieAuth authenticates to the control port using the content of
+// the cookie file, see the CookieAuthentication and CookieAuthFile options
+// of tor.
+func CookieAuth(cookieFile string) ControlAuth {
+	fun := func(ctx context.Context, ctrl *Controller) error {
+		cookie, err := os.ReadFile(cookieFile)
+		if err != nil {
+			const format = "cannot read control auth cookie: %v"
+			return fmt.Errorf(format, err)
+		}
+
+		_, err = ctrl.do(ctx, "AUTHENTICATE %s", hex.EncodeToString(cookie))
+
+		return err
+	}
+
+	return controlAuthFunc(fun)
+}
+
+// PasswordAuth authenticates to the control port using the password, see
+// the HashedControlPassword option of tor.
+func PasswordAuth(password string) ControlAuth {
+	fun := func(ctx context.Context, ctrl *Controller) error {
+		_, err := ctrl.do(ctx, "AUTHENTICATE %s", quoteControlString(password))
+		return err
+	}
+
+	return controlAuthFunc(fun)
+}
+
+// NullAuth authenticates to the control port which does not require
+// authentication.
+func NullAuth() ControlAuth {
+	fun := func(ctx context.Context, ctrl *Controller) error {
+		_, err := ctrl.do(ctx, "AUTHENTICATE")
+		return err
+	}
+
+	return controlAuthFunc(fun)
+}
+
+// dialController connects to the control port of the tor demon and
+// authenticates using the auth method.
+func dialController(ctx context.Context, addr endpoint, auth ControlAuth) (*Controller, error) {
+	var dr net.Dialer
+
+	conn, err := dr.DialContext(ctx, addr.network, addr.address)
+	if err != nil {
+		const format = "cannot connect to control port %q: %v"
+		return nil, fmt.Errorf(format, addr.address, err)
+	}
+
+	ctrl := newController(conn)
+
+	if auth == nil {
+		auth = NullAuth()
+	}
+
+	if err := auth.authenticate(ctx, ctrl); err != nil {
+		_ = ctrl.Close()
+
+		const format = "cannot authenticate to control port: %v"
+
+		return nil, fmt.Errorf(format, err)
+	}
+
+	return ctrl, nil
+}
+
+func newController(conn net.Conn) *Controller {
+	cc := &controlConn{
+		conn: conn,
+		text: textproto.NewConn(conn),
+		done: make(chan struct{}),
+	}
+
+	go cc.readLoop()
+
+	return &Controller{conn: cc}
+}
+
+// Signal sends a signal to the tor demon, see the description of
+// the SIGNAL command in the control protocol specification for
+// the list of supported signals. The signal must be a keyword of
+// uppercase letters and digits, e.g. "NEWNYM".
+func (c *Controller) Signal(ctx context.Context, signal string) error {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	// Line breaks in the signal would inject arbitrary commands.
+	if !signalRegexp.MatchString(signal) {
+		const format = "tornado: invalid signal %q"
+		return fmt.Errorf(format, signal)
+	}
+
+	_, err := c.do(ctx, "SIGNAL %s", signal)
+
+	return err
+}
+
+// NewIdentity switches to clean circuits, so new application requests
+// don't share any circuits with old ones.
+//
+// Keep in mind that tor applies rate limiting to this signal, and it
+// affects all proxies served by the same tor demon.
+func (c *Controller) NewIdentity(ctx context.Context) error {
+	return c.Signal(ctx, "NEWNYM")
+}
+
+// Close closes the control connection.
+func (c *Controller) Close() (err error) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		cc := c.conn
+		c.mu.Unlock()
+
+		err = cc.close()
+	})
+
+	return err
+}
+
+// replace replaces the connection of the Controller with the connection of
+// the other one, it is used to reconnect to the restarted tor demon while
+// keeping the Controller valid. The other Controller must not be used
+// after that.
+func (c *Controller) replace(other *Controller) {
+	c.mu.Lock()
+
+	if c.closed {
+		c.mu.Unlock()
+		_ = other.conn.close()
+
+		return
+	}
+
+	old := c.conn
+	c.conn = other.conn
+	c.mu.Unlock()
+
+	_ = old.close()
+}
+
+// do sends the command and waits for the reply to it. The reply is
+// considered successful only if it has the 250 status code.
+func (c *Controller) do(ctx context.Context, command string, args ...any) (controlReply, error) {
+	c.mu.Lock()
+	cc := c.conn
+	c.mu.Unlock()
+
+	return cc.do(ctx, command, args...)
+}
+
+func (cc *controlConn) close() error {
+	err := cc.conn.Close()
+	<-cc.done
+
+	return err
+}
+
+func (cc *controlConn) do(ctx context.Context, command string, args ...any) (controlReply, error) {
+	keyword, _, _ := strings.Cut(command, " ")
+	reply := make(chan controlReply, 1)
+
+	cc.mu.Lock()
+
+	select {
+	case <-cc.done:
+		cc.mu.Unlock()
+
+		const format = "cannot send command %s: %v"
+
+		return controlReply{}, fmt.Errorf(format, keyword, cc.err)
+	default:
+	}
+
+	if err := cc.text.PrintfLine(command, args...); err != nil {
+		cc.mu.Unlock()
+
+		const format = "cannot send command %s: %v"
+
+		return controlReply{}, fmt.Errorf(format, keyword, err)
+	}
+
+	cc.pending = append(cc.pending, reply)
+	cc.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return controlReply{}, ctx.Err()
+	case <-cc.done:
+		const format = "cannot receive reply to command %s: %v"
+		return controlReply{}, fmt.Errorf(format, keyword, cc.err)
+	case rpl := <-reply:
+		if rpl.code != 250 {
+			const format = "command %s failed: %s"
+			return rpl, fmt.Errorf(format, keyword, rpl)
+		}
+
+		return rpl, nil
+	}
+}
+
+func (cc *controlConn) readLoop() {
+	defer close(cc.done)
+
+	for {
+		rpl, err := readControlReply(&cc.text.Reader)
+		if err != nil {
+			cc.err = err
+			return
+		}
+
+		// Asynchronous events are not subscribed to, so they are
+		// skipped if tor sends them anyway.
+		if rpl.code == 650 {
+			continue
+		}
+
+		cc.mu.Lock()
+
+		if len(cc.pending) == 0 {
+			cc.mu.Unlock()
+			continue
+		}
+
+		reply := cc.pending[0]
+		cc.pending = cc.pending[1:]
+		cc.mu.Unlock()
+
+		reply <- rpl
+	}
+}
+
+// readControlReply reads a single, possibly multi-line, reply.
+func readControlReply(r *textproto.Reader) (rpl controlReply, err error) {
+	for {
+		line, err := r.ReadLine()
+		if err != nil {
+			return controlReply{}, err
+		}
+
+		if len(line) < 4 {
+			const format = "malformed control reply line %q"
+			return controlReply{}, fmt.Errorf(format, line)
+		}
+
+		code, err := strconv.Atoi(line[:3])
+		if err != nil {
+			const format = "malformed status code in control reply line %q"
+			return controlReply{}, fmt.Errorf(format, line)
+		}
+
+		rpl.code = code
+
+		switch text := line[4:]; line[3] {
+		case ' ':
+			rpl.lines = append(rpl.lines, text)
+			return rpl, nil
+		case '-':
+			rpl.lines = append(rpl.lines, text)
+		case '+':
+			data, err := r.ReadDotLines()
+			if err != nil {
+				return controlReply{}, err
+			}
+
+			rpl.lines = append(rpl.lines, text+"\n"+strings.Join(data, "\n"))
+		default:
+			const format = "malformed separator in control reply line %q"
+			return controlReply{}, fmt.Errorf(format, line)
+		}
+	}
+}
+
+// quoteControlString returns the string quoted according to the rules of
+// the QuotedString of the control protocol.
+func quoteControlString(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for _, c := range []byte(s) {
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
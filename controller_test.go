@@ -0,0 +1,175 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"testing"
+)
+
+// newTestController creates a Controller connected to the fake control
+// port served by the handler.
+func newTestController(t *testing.T, handler func(srv *textproto.Conn)) *Controller {
+	t.Helper()
+
+	client, server := net.Pipe()
+
+	go func() {
+		defer server.Close()
+
+		handler(textproto.NewConn(server))
+	}()
+
+	ctrl := newController(client)
+	t.Cleanup(func() {
+		_ = ctrl.Close()
+	})
+
+	return ctrl
+}
+
+func TestController_NewIdentity(t *testing.T) {
+	t.Parallel()
+	t.Run("Should send the NEWNYM signal", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		received := make(chan string, 1)
+		ctrl := newTestController(t, func(srv *textproto.Conn) {
+			line, _ := srv.ReadLine()
+			received <- line
+
+			_ = srv.PrintfLine("250 OK")
+		})
+
+		// act
+		err := ctrl.NewIdentity(context.Background())
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if line := <-received; line != "SIGNAL NEWNYM" {
+			t.Fatalf("unexpected command %q", line)
+		}
+	})
+
+	t.Run("Should return an error on a failure reply", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		ctrl := newTestController(t, func(srv *textproto.Conn) {
+			_, _ = srv.ReadLine()
+			_ = srv.PrintfLine("552 Unrecognized signal code \"NEWNYM\"")
+		})
+
+		// act
+		err := ctrl.NewIdentity(context.Background())
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
+
+func TestController_Signal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		signal string
+	}{
+		{name: "Should reject signal with injected command", signal: "NEWNYM\r\nSETCONF SocksPort=0"},
+		{name: "Should reject signal with line feed", signal: "NEWNYM\nTAKEOWNERSHIP"},
+		{name: "Should reject signal with space", signal: "NEWNYM ACTIVE"},
+		{name: "Should reject empty signal", signal: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			received := make(chan string, 1)
+			ctrl := newTestController(t, func(srv *textproto.Conn) {
+				line, err := srv.ReadLine()
+				if err == nil {
+					received <- line
+				}
+			})
+
+			// act
+			err := ctrl.Signal(context.Background(), tt.signal)
+
+			// assert
+			if err == nil {
+				t.Fatal("an error was expected")
+			}
+
+			select {
+			case line := <-received:
+				t.Fatalf("no command should be sent, got %q", line)
+			default:
+			}
+		})
+	}
+}
+
+func TestController_do(t *testing.T) {
+	t.Parallel()
+	t.Run("Should read multi-line replies and skip asynchronous events", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		ctrl := newTestController(t, func(srv *textproto.Conn) {
+			_, _ = srv.ReadLine()
+			_ = srv.PrintfLine("650 CIRC 1 LAUNCHED")
+			_ = srv.PrintfLine("250-version=0.4.8.10")
+			_ = srv.PrintfLine("250+config-text=")
+			_ = srv.PrintfLine("SocksPort 9050")
+			_ = srv.PrintfLine("..leading dot")
+			_ = srv.PrintfLine(".")
+			_ = srv.PrintfLine("250 OK")
+		})
+
+		// act
+		rpl, err := ctrl.do(context.Background(), "GETINFO version config-text")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		want := []string{
+			"version=0.4.8.10",
+			"config-text=\nSocksPort 9050\n.leading dot",
+			"OK",
+		}
+
+		if len(rpl.lines) != len(want) {
+			t.Fatalf("got %q, want %q", rpl.lines, want)
+		}
+
+		for i := range want {
+			if rpl.lines[i] != want[i] {
+				t.Fatalf("got %q, want %q", rpl.lines, want)
+			}
+		}
+	})
+
+	t.Run("Should return an error when the connection is lost", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		ctrl := newTestController(t, func(srv *textproto.Conn) {
+			_, _ = srv.ReadLine()
+		})
+
+		// act
+		_, err := ctrl.do(context.Background(), "GETINFO version")
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
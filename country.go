@@ -0,0 +1,254 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// isoCountryCodes is the list of the officially assigned ISO 3166-1
+// alpha-2 country codes.
+const isoCountryCodes = "" +
+	"ad ae af ag ai al am ao aq ar as at au aw ax az ba bb bd be " +
+	"bf bg bh bi bj bl bm bn bo bq br bs bt bv bw by bz ca cc cd " +
+	"cf cg ch ci ck cl cm cn co cr cu cv cw cx cy cz de dj dk dm " +
+	"do dz ec ee eg eh er es et fi fj fk fm fo fr ga gb gd ge gf " +
+	"gg gh gi gl gm gn gp gq gr gs gt gu gw gy hk hm hn hr ht hu " +
+	"id ie il im in io iq ir is it je jm jo jp ke kg kh ki km kn " +
+	"kp kr kw ky kz la lb lc li lk lr ls lt lu lv ly ma mc md me " +
+	"mf mg mh mk ml mm mn mo mp mq mr ms mt mu mv mw mx my mz na " +
+	"nc ne nf ng ni nl no np nr nu nz om pa pe pf pg ph pk pl pm " +
+	"pn pr ps pt pw py qa re ro rs ru rw sa sb sc sd se sg sh si " +
+	"sj sk sl sm sn so sr ss st sv sx sy sz tc td tf tg th tj tk " +
+	"tl tm tn to tr tt tv tw tz ua ug um us uy uz va vc ve vg vi " +
+	"vn vu wf ws ye yt za zm zw"
+
+var countryCodes = func() map[string]bool {
+	codes := make(map[string]bool)
+
+	for _, code := range strings.Fields(isoCountryCodes) {
+		codes[code] = true
+	}
+
+	return codes
+}()
+
+// validateCountryCode validates the ISO 3166-1 alpha-2 country code.
+func validateCountryCode(code string) error {
+	if !countryCodes[strings.ToLower(code)] {
+		const format = "%q is not an ISO 3166-1 alpha-2 country code"
+		return fmt.Errorf(format, code)
+	}
+
+	return nil
+}
+
+// countryNodes returns the country codes in the format of ExitNodes, e.g.
+// "{us}".
+func countryNodes(codes []string) []string {
+	nodes := make([]string, 0, len(codes))
+
+	for _, code := range codes {
+		nodes = append(nodes, "{"+strings.ToLower(code)+"}")
+	}
+
+	return nodes
+}
+
+// WithExitCountries allows to use only the exit nodes located in
+// the countries, the codes are ISO 3166-1 alpha-2 country codes, e.g. "us"
+// or "DE". Invalid codes are reported by NewProxy and NewPool.
+//
+// The option adds the countries to Config.ExitNodes and enables
+// Config.StrictNodes, so WithConfig used after it replaces them.
+//
+// Tor applies the exit nodes to the whole tor demon, so all proxies of
+// the Pool exit from the same countries, use NewCountryPool to have
+// proxies exiting from different countries in the same Pool.
+func WithExitCountries(codes ...string) Option {
+	fun := func(s *options) {
+		s.config.ExitNodes = slices.Concat(s.config.ExitNodes, countryNodes(codes))
+		s.config.StrictNodes = true
+	}
+
+	return optionFunc(fun)
+}
+
+// WithExcludeExitCountries allows to never use the exit nodes located in
+// the countries, see WithExitCountries.
+//
+// The option adds the countries to Config.ExcludeExitNodes, tor enforces
+// them for exits without Config.StrictNodes, so it is left unchanged.
+func WithExcludeExitCountries(codes ...string) Option {
+	fun := func(s *options) {
+		s.config.ExcludeExitNodes = slices.Concat(s.config.ExcludeExitNodes, countryNodes(codes))
+	}
+
+	return optionFunc(fun)
+}
+
+// NewCountryPool creates new instance of proxy Pool with one proxy per
+// country, each proxy uses only the exit nodes located in its country, see
+// WithExitCountries and Proxy.ExitCountries.
+//
+// Tor has no per-SocksPort ExitNodes, the exit nodes apply to the whole
+// tor demon, so the proxies of a single tor demon can't exit from
+// different countries. Therefore, NewCountryPool launches a separate tor
+// demon for each country: N countries cost N tor processes, each of them
+// has its own memory usage, bootstrapping and directory cache, so
+// the Pool requires about N times more memory and time to start than
+// NewPool. The tor demons are launched concurrently, so WithDataDirectory
+// can't be used with NewCountryPool.
+//
+// The Pool has no Controller, use the Controller of its proxies instead,
+// NewIdentity of the Pool applies to all tor demons. Done of the Pool is
+// closed when any of the tor demons exits, and Err returns its error.
+func NewCountryPool(ctx context.Context, countries []string, ops ...Option) (*Pool, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if len(countries) == 0 {
+		return nil, errors.New("tornado: at least one country is required")
+	}
+
+	members := make([]*Pool, len(countries))
+	errs := make([]error, len(countries))
+
+	var wg sync.WaitGroup
+
+	for i, country := range countries {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := validateCountryCode(country); err != nil {
+				errs[i] = fmt.Errorf("tornado: %w", err)
+				return
+			}
+
+			members[i], errs[i] = NewPool(ctx, 1, append(slices.Clip(ops), WithExitCountries(country))...)
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		_ = closeMembers(members)
+
+		const format = "cannot create country pool: %w"
+
+		return nil, fmt.Errorf(format, err)
+	}
+
+	return newCountryPool(members), nil
+}
+
+// newCountryPool creates the Pool owning the proxies of the members.
+func newCountryPool(members []*Pool) *Pool {
+	closeFunc := func() error {
+		return closeMembers(members)
+	}
+
+	pool := newFreePool(len(members), nil, closeFunc)
+	pool.members = members
+	pool.exit = watchMembers(pool.life, members)
+	pool.retryPolicy = members[0].retryPolicy
+	pool.balancer = members[0].balancer
+
+	for _, member := range members {
+		// The member pool was just created, so its proxy is available.
+		prx, _ := member.TryGet()
+		pool.add(prx)
+	}
+
+	return pool
+}
+
+// closeMembers closes the members, which were created, and returns
+// the first error.
+func closeMembers(members []*Pool) (err error) {
+	for _, member := range members {
+		if member == nil {
+			continue
+		}
+
+		if closeErr := member.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// memberExit tracks the first member of the country pool, which stopped
+// working. It must not reference the country pool, otherwise the finalizer
+// of the pool never runs.
+type memberExit struct {
+	done   chan struct{}
+	once   sync.Once
+	member *Pool
+}
+
+// watchMembers returns the memberExit, which done channel is closed when
+// the lifetime ends or any of the members stops working.
+func watchMembers(life *lifetime, members []*Pool) *memberExit {
+	exit := &memberExit{done: make(chan struct{})}
+
+	for _, member := range members {
+		go func() {
+			select {
+			case <-member.Done():
+				exit.once.Do(func() {
+					exit.member = member
+					close(exit.done)
+				})
+			case <-life.done:
+				exit.once.Do(func() {
+					close(exit.done)
+				})
+			case <-exit.done:
+			}
+		}()
+	}
+
+	return exit
+}
+
+// err returns the error of the member, which stopped working, or nil if
+// the lifetime ended first. It must be called after done is closed.
+func (e *memberExit) err() error {
+	if e.member == nil {
+		return nil
+	}
+
+	return e.member.Err()
+}
+
+// ExitCountries returns the ISO 3166-1 alpha-2 codes of the countries of
+// the exit nodes used by the proxy, it is empty if the exit nodes are not
+// restricted by country.
+func (p *Proxy) ExitCountries() []string {
+	return slices.Clone(p.exitCountries)
+}
+
+// exitCountries returns the country codes from ExitNodes.
+func (c Config) exitCountries() []string {
+	var codes []string
+
+	for _, node := range c.ExitNodes {
+		if code, ok := strings.CutPrefix(node, "{"); ok {
+			codes = append(codes, strings.TrimSuffix(code, "}"))
+		}
+	}
+
+	return codes
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithExitCountries(t *testing.T) {
+	t.Parallel()
+	t.Run("Should render countries with strict nodes", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var state options
+
+		// act
+		WithExitCountries("us", "DE").apply(&state)
+		WithExcludeExitCountries("ru").apply(&state)
+
+		// assert
+		want := []string{
+			"ExitNodes {us},{de}",
+			"ExcludeExitNodes {ru}",
+			"StrictNodes 1",
+		}
+
+		if got := state.config.torrcLines(); !slices.Equal(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+
+		if got := state.config.exitCountries(); !slices.Equal(got, []string{"us", "de"}) {
+			t.Fatalf("got exit countries %q", got)
+		}
+	})
+
+	t.Run("Should reject unknown country code", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var state options
+
+		WithExitCountries("us", "xx").apply(&state)
+
+		// act
+		err := state.config.Validate()
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+
+	t.Run("Should not make excluded exit countries strict", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var state options
+
+		// act
+		WithExcludeExitCountries("ru").apply(&state)
+
+		// assert
+		if state.config.StrictNodes {
+			t.Fatal("strict nodes should not be enabled")
+		}
+
+		if got, want := state.config.torrcLines(), []string{"ExcludeExitNodes {ru}"}; !slices.Equal(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestNewCountryPool(t *testing.T) {
+	t.Parallel()
+	t.Run("Should reject empty list of countries", func(t *testing.T) {
+		t.Parallel()
+		// act
+		_, err := NewCountryPool(context.Background(), nil)
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+
+	t.Run("Should reject unknown country code", func(t *testing.T) {
+		t.Parallel()
+		// act
+		_, err := NewCountryPool(context.Background(), []string{"usa"})
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+
+	t.Run("Should not share exit nodes of config between members", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dir := t.TempDir()
+		binary := filepath.Join(t.TempDir(), "tor")
+		script := "#!/bin/sh\ncp \"$2\" " + dir + "/torrc.$$\nexit 1\n"
+
+		if err := os.WriteFile(binary, []byte(script), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		countries := []string{"us", "de", "fr", "nl"}
+		cfg := Config{ExitNodes: make([]string, 0, len(countries))}
+
+		// act
+		_, err := NewCountryPool(context.Background(), countries, WithTorBinary(binary), WithConfig(cfg))
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "torrc.*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(line, "ExitNodes ") {
+					got = append(got, line)
+				}
+			}
+		}
+
+		slices.Sort(got)
+
+		want := []string{"ExitNodes {de}", "ExitNodes {fr}", "ExitNodes {nl}", "ExitNodes {us}"}
+		if !slices.Equal(got, want) {
+			t.Fatalf("got exit nodes of members %q, want %q", got, want)
+		}
+	})
+
+	newTestMembers := func(t *testing.T) ([]*Pool, *daemon) {
+		t.Helper()
+
+		members := []*Pool{newTestPool(t, 1), newTestPool(t, 1)}
+		tor, _, _ := newTestDaemon(t, nil)
+		members[1].tor = tor
+
+		return members, tor
+	}
+
+	t.Run("Should report exit of tor demon of any member", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		members, tor := newTestMembers(t)
+		pool := newCountryPool(members)
+		t.Cleanup(func() {
+			_ = pool.Close()
+		})
+
+		if err := pool.Err(); err != nil {
+			t.Fatal("should not get an error before exit:", err)
+		}
+
+		// act
+		killTestDaemon(t, tor)
+
+		// assert
+		<-pool.Done()
+
+		err := pool.Err()
+		if err == nil || errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("got error %v, want exit error of tor demon", err)
+		}
+	})
+
+	t.Run("Should report ErrPoolClosed after close", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		members, _ := newTestMembers(t)
+		pool := newCountryPool(members)
+
+		// act
+		_ = pool.Close()
+
+		// assert
+		<-pool.Done()
+
+		if err := pool.Err(); !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("got error %v, want %v", err, ErrPoolClosed)
+		}
+	})
+}
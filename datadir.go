@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrDataDirectoryLocked is returned by NewProxy and NewPool when the data
+// directory specified via WithDataDirectory is used by another running
+// instance of tornado.
+var ErrDataDirectoryLocked = errors.New("tornado: data directory is used by another tornado instance")
+
+const dataDirectoryLockFile = "tornado.lock"
+
+// WithDataDirectory allows to use the persistent data directory instead of
+// the temporary one. Tor keeps the cached consensus, descriptors and entry
+// guards in the data directory, so reusing it speeds up the bootstrapping
+// and keeps the entry guards stable.
+//
+// The directory is created if it does not exist, and is not removed when
+// the Proxy or the Pool is closed. The directory can't be shared by several
+// running instances of Proxy or Pool.
+func WithDataDirectory(dir string) Option {
+	fun := func(s *options) {
+		s.dataDirectory = dir
+	}
+
+	return optionFunc(fun)
+}
+
+// lockDataDirectory creates the lock file in the data directory, so that
+// no other instance of tornado can use it at the same time. The lock left
+// by the process that is no longer running is considered stale and is
+// taken over.
+func lockDataDirectory(dir string) (lockFile string, err error) {
+	lockFile = filepath.Join(dir, dataDirectoryLockFile)
+	pid := []byte(strconv.Itoa(os.Getpid()))
+
+	for range 2 {
+		file, err := os.OpenFile(lockFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			if _, err := file.Write(pid); err != nil {
+				_ = file.Close()
+				_ = os.Remove(lockFile)
+
+				const format = "cannot write lock file: %v"
+
+				return "", fmt.Errorf(format, err)
+			}
+
+			if err := file.Close(); err != nil {
+				_ = os.Remove(lockFile)
+
+				const format = "cannot close lock file: %v"
+
+				return "", fmt.Errorf(format, err)
+			}
+
+			return lockFile, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			const format = "cannot create lock file: %v"
+			return "", fmt.Errorf(format, err)
+		}
+
+		data, err := os.ReadFile(lockFile)
+		if err != nil {
+			const format = "cannot read lock file: %v"
+			return "", fmt.Errorf(format, err)
+		}
+
+		owner, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err == nil && (owner == os.Getpid() || processExists(owner)) {
+			const format = "%w: locked by process %d"
+			return "", fmt.Errorf(format, ErrDataDirectoryLocked, owner)
+		}
+
+		if err := os.Remove(lockFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			const format = "cannot remove stale lock file: %v"
+			return "", fmt.Errorf(format, err)
+		}
+	}
+
+	return "", ErrDataDirectoryLocked
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestWithDataDirectory(t *testing.T) {
+	t.Parallel()
+	t.Run("Should keep the directory but remove tornado files on cleanup", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dir := filepath.Join(t.TempDir(), "data")
+		state := options{numberOfProxy: 1}
+		WithDataDirectory(dir).apply(&state)
+
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = trc.cleanup()
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal("data directory should be kept:", err)
+		}
+
+		if len(entries) != 0 {
+			t.Fatalf("torrc and lock files should be removed, got %v", entries)
+		}
+	})
+
+	t.Run("Should not allow two instances to share the directory", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		state := options{numberOfProxy: 1}
+		WithDataDirectory(t.TempDir()).apply(&state)
+
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			_ = trc.cleanup()
+		})
+
+		// act
+		_, err = newTorrcFromState(state)
+
+		// assert
+		if !errors.Is(err, ErrDataDirectoryLocked) {
+			t.Fatalf("ErrDataDirectoryLocked error was expected, but got another one: %v", err)
+		}
+	})
+}
+
+func Test_lockDataDirectory(t *testing.T) {
+	t.Parallel()
+	t.Run("Should take over the lock of the process that is not running", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dir := t.TempDir()
+
+		cmd := exec.Command("true")
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		stale := []byte(strconv.Itoa(cmd.Process.Pid))
+		if err := os.WriteFile(filepath.Join(dir, dataDirectoryLockFile), stale, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		lockFile, err := lockDataDirectory(dir)
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		data, err := os.ReadFile(lockFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != strconv.Itoa(os.Getpid()) {
+			t.Fatalf("lock file should contain pid %d, got %q", os.Getpid(), data)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"fmt"
+)
+
+// SOCKSReply is the reply code of the SOCKS5 server to the failed request,
+// it is an error, so the constants below can be used with errors.Is to
+// check the reason of the failure of DialContext, e.g.
+//
+//	if errors.Is(err, tornado.ErrHostUnreachable) {
+//		// ...
+//	}
+//
+// See https://spec.torproject.org/socks-extensions.html for the meaning
+// of the codes in tor.
+type SOCKSReply byte
+
+// The reply codes of RFC 1928.
+const (
+	ErrGeneralFailure         SOCKSReply = 0x01
+	ErrNotAllowed             SOCKSReply = 0x02
+	ErrNetworkUnreachable     SOCKSReply = 0x03
+	ErrHostUnreachable        SOCKSReply = 0x04
+	ErrConnectionRefused      SOCKSReply = 0x05
+	ErrTTLExpired             SOCKSReply = 0x06
+	ErrCommandNotSupported    SOCKSReply = 0x07
+	ErrAddressTypeUnsupported SOCKSReply = 0x08
+)
+
+// The extended reply codes of tor for onion services, they are enabled by
+// the ExtendedErrors flag of the SocksPort, which is set by NewProxy and
+// NewPool.
+const (
+	ErrOnionDescriptorNotFound SOCKSReply = 0xF0
+	ErrOnionDescriptorInvalid  SOCKSReply = 0xF1
+	ErrOnionIntroFailed        SOCKSReply = 0xF2
+	ErrOnionRendezvousFailed   SOCKSReply = 0xF3
+	ErrOnionMissingClientAuth  SOCKSReply = 0xF4
+	ErrOnionWrongClientAuth    SOCKSReply = 0xF5
+	ErrOnionBadAddress         SOCKSReply = 0xF6
+	ErrOnionIntroTimeout       SOCKSReply = 0xF7
+)
+
+var socksReplyText = map[SOCKSReply]string{
+	ErrGeneralFailure:          "general SOCKS server failure",
+	ErrNotAllowed:              "connection not allowed by ruleset",
+	ErrNetworkUnreachable:      "network unreachable",
+	ErrHostUnreachable:         "host unreachable",
+	ErrConnectionRefused:       "connection refused",
+	ErrTTLExpired:              "TTL expired",
+	ErrCommandNotSupported:     "command not supported",
+	ErrAddressTypeUnsupported:  "address type not supported",
+	ErrOnionDescriptorNotFound: "onion service descriptor can not be found",
+	ErrOnionDescriptorInvalid:  "onion service descriptor is invalid",
+	ErrOnionIntroFailed:        "onion service introduction failed",
+	ErrOnionRendezvousFailed:   "onion service rendezvous failed",
+	ErrOnionMissingClientAuth:  "onion service missing client authorization",
+	ErrOnionWrongClientAuth:    "onion service wrong client authorization",
+	ErrOnionBadAddress:         "onion service invalid address",
+	ErrOnionIntroTimeout:       "onion service introduction timed out",
+}
+
+func (r SOCKSReply) Error() string {
+	text, ok := socksReplyText[r]
+	if !ok {
+		text = "unknown reply"
+	}
+
+	const format = "%s (SOCKS reply %#02x)"
+
+	return fmt.Sprintf(format, text, byte(r))
+}
+
+// A DialError describes the failure of DialContext of the Proxy.
+type DialError struct {
+	// Network and Address are the arguments of DialContext.
+	Network string
+	Address string
+	// Reply is the reply code of the SOCKS5 server, it is zero if
+	// the failure happened before the reply, e.g. the SocksPort is not
+	// reachable or the context is done.
+	Reply SOCKSReply
+	// Err is the cause of the failure, it is Reply if the reply code is
+	// not zero.
+	Err error
+}
+
+func (e *DialError) Error() string {
+	const format = "tornado: dial %s %s over tor: %v"
+	return fmt.Sprintf(format, e.Network, e.Address, e.Err)
+}
+
+func (e *DialError) Unwrap() error {
+	return e.Err
+}
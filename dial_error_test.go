@@ -0,0 +1,185 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xorcare/tornado/internal/deadlock"
+)
+
+func TestProxy_DialContext_DialError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		reply   byte
+		address string
+		want    SOCKSReply
+	}{
+		{
+			name:    "Should return host unreachable reply",
+			reply:   0x04,
+			address: "example.com:80",
+			want:    ErrHostUnreachable,
+		},
+		{
+			name:    "Should return TTL expired reply",
+			reply:   0x06,
+			address: "example.com:80",
+			want:    ErrTTLExpired,
+		},
+		{
+			name:    "Should return extended onion descriptor reply",
+			reply:   0xF0,
+			address: "2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wid.onion:80",
+			want:    ErrOnionDescriptorNotFound,
+		},
+		{
+			name:    "Should return extended missing client authorization reply",
+			reply:   0xF4,
+			address: "2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wid.onion:80",
+			want:    ErrOnionMissingClientAuth,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			addr, _ := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", tt.reply)
+
+			prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			_, err = prx.DialContext(context.Background(), "tcp", tt.address)
+
+			// assert
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+
+			var dialErr *DialError
+			if !errors.As(err, &dialErr) {
+				t.Fatalf("got error %T, want *DialError", err)
+			}
+
+			if dialErr.Reply != tt.want || dialErr.Address != tt.address || dialErr.Network != "tcp" {
+				t.Fatalf("unexpected dial error %+v", dialErr)
+			}
+		})
+	}
+
+	t.Run("Should wrap error of forward dialer", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, err := openSOCKS5Proxy(tcpEndpoint(9050), deadlock.Dealer{}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		_, err = prx.DialContext(context.Background(), "tcp", "example.com:80")
+
+		// assert
+		if !errors.Is(err, deadlock.ErrDeadlockDial) {
+			t.Fatalf("got error %v, want %v", err, deadlock.ErrDeadlockDial)
+		}
+
+		var dialErr *DialError
+		if !errors.As(err, &dialErr) || dialErr.Reply != 0 {
+			t.Fatalf("got error %v, want *DialError without reply", err)
+		}
+	})
+
+	t.Run("Should return error for unsupported network", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, err := openSOCKS5Proxy(tcpEndpoint(9050), nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		_, err = prx.DialContext(context.Background(), "udp", "example.com:53")
+
+		// assert
+		var dialErr *DialError
+		if !errors.As(err, &dialErr) {
+			t.Fatalf("got error %v, want *DialError", err)
+		}
+	})
+}
+
+func TestSOCKSReply_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		reply SOCKSReply
+		want  string
+	}{
+		{
+			name:  "Should describe standard reply",
+			reply: ErrConnectionRefused,
+			want:  "connection refused (SOCKS reply 0x05)",
+		},
+		{
+			name:  "Should describe extended reply",
+			reply: ErrOnionIntroTimeout,
+			want:  "onion service introduction timed out (SOCKS reply 0xf7)",
+		},
+		{
+			name:  "Should describe unknown reply",
+			reply: 0x42,
+			want:  "unknown reply (SOCKS reply 0x42)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// act
+			got := tt.reply.Error()
+
+			// assert
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTorrcFromState_ExtendedErrors(t *testing.T) {
+	t.Parallel()
+	t.Run("Should enable extended errors on each socks port", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		state := options{numberOfProxy: 2}
+
+		// act
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = trc.cleanup()
+		})
+
+		// assert
+		for _, addr := range trc.socksAddress {
+			if !strings.Contains(trc.torrc, "SocksPort "+addr.address+" ExtendedErrors\n") {
+				t.Fatalf("torrc does not enable extended errors for %s:\n%s", addr.address, trc.torrc)
+			}
+		}
+	})
+}
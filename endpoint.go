@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+)
+
+// maxUnixSocketPath is the lowest limit of the length of the unix socket
+// path among the supported platforms, including the terminating zero.
+const maxUnixSocketPath = 104
+
+// endpoint is the address of a listener of the tor demon.
+type endpoint struct {
+	network string
+	address string
+}
+
+func tcpEndpoint(port int) endpoint {
+	return endpoint{
+		network: "tcp",
+		address: net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+	}
+}
+
+func unixEndpoint(dir, name string) (endpoint, error) {
+	path := filepath.Join(dir, name)
+	if len(path) >= maxUnixSocketPath {
+		const format = "unix socket path %q is too long, it must be shorter than %d bytes"
+		return endpoint{}, fmt.Errorf(format, path, maxUnixSocketPath)
+	}
+
+	return endpoint{network: "unix", address: path}, nil
+}
+
+// torrcValue returns the value of the *Port option of torrc for
+// the endpoint.
+func (e endpoint) torrcValue() string {
+	if e.network == "unix" {
+		return "unix:" + e.address
+	}
+
+	return e.address
+}
+
+// WithUnixSockets makes the tor demon listen on unix domain sockets in
+// the data directory instead of TCP ports for SOCKS and control
+// connections. It eliminates the race for free ports between tornado and
+// other processes, and keeps the proxy private to the file system
+// permissions of the data directory.
+//
+// The custom forward dialer specified via WithForwardContextDialer must
+// support the "unix" network.
+func WithUnixSockets() Option {
+	fun := func(s *options) {
+		s.unixSockets = true
+	}
+
+	return optionFunc(fun)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// socks5Request is the request received by the fake SOCKS5 server.
+type socks5Request struct {
+	username string
+	password string
+	command  byte
+	host     string
+	port     uint16
+}
+
+// testSOCKS5PTRName is the name of the reply to the RESOLVE_PTR command.
+const testSOCKS5PTRName = "ptr.example.com"
+
+// newTestSOCKS5Server starts a minimal SOCKS5 server, which replies with
+// the reply code to every request and then echoes the data back.
+func newTestSOCKS5Server(t *testing.T, network, address string, reply byte) (endpoint, <-chan socks5Request) {
+	t.Helper()
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	requests := make(chan socks5Request, 16)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveTestSOCKS5(conn, reply, requests)
+		}
+	}()
+
+	return endpoint{network: network, address: listener.Addr().String()}, requests
+}
+
+func serveTestSOCKS5(conn net.Conn, reply byte, requests chan<- socks5Request) {
+	defer conn.Close()
+
+	var req socks5Request
+
+	buf := make([]byte, 512)
+
+	// Greeting: VER NMETHODS METHODS.
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+
+	methods := buf[2 : 2+buf[1]]
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+
+	method := byte(0x00)
+	if strings.ContainsRune(string(methods), 0x02) {
+		method = 0x02
+	}
+
+	if _, err := conn.Write([]byte{0x05, method}); err != nil {
+		return
+	}
+
+	// Username/password authentication, RFC 1929.
+	if method == 0x02 {
+		if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+			return
+		}
+
+		user := make([]byte, buf[1])
+		if _, err := io.ReadFull(conn, user); err != nil {
+			return
+		}
+
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return
+		}
+
+		pass := make([]byte, buf[0])
+		if _, err := io.ReadFull(conn, pass); err != nil {
+			return
+		}
+
+		req.username, req.password = string(user), string(pass)
+
+		if _, err := conn.Write([]byte{0x01, 0x00}); err != nil {
+			return
+		}
+	}
+
+	// Request: VER CMD RSV ATYP DST.ADDR DST.PORT.
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return
+	}
+
+	req.command = buf[1]
+
+	switch buf[3] {
+	case 0x01:
+		if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+			return
+		}
+
+		req.host = net.IP(buf[:4]).String()
+	case 0x03:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return
+		}
+
+		host := make([]byte, buf[0])
+		if _, err := io.ReadFull(conn, host); err != nil {
+			return
+		}
+
+		req.host = string(host)
+	case 0x04:
+		if _, err := io.ReadFull(conn, buf[:16]); err != nil {
+			return
+		}
+
+		req.host = net.IP(buf[:16]).String()
+	}
+
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+
+	req.port = binary.BigEndian.Uint16(buf[:2])
+	requests <- req
+
+	resp := []byte{0x05, reply, 0x00, 0x01, 127, 0, 0, 1, 0, 0}
+
+	// The RESOLVE_PTR extension of tor replies with the host name.
+	if req.command == 0xF1 {
+		resp = append([]byte{0x05, reply, 0x00, 0x03, byte(len(testSOCKS5PTRName))}, testSOCKS5PTRName...)
+		resp = append(resp, 0, 0)
+	}
+
+	if _, err := conn.Write(resp); err != nil {
+		return
+	}
+
+	if reply == 0x00 && req.command == 0x01 {
+		_, _ = io.Copy(conn, conn)
+	}
+}
+
+func TestWithUnixSockets(t *testing.T) {
+	t.Parallel()
+	t.Run("Should configure SOCKS and control ports on unix sockets", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		state := options{numberOfProxy: 2}
+		WithUnixSockets().apply(&state)
+
+		// act
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = trc.cleanup()
+		})
+
+		// assert
+		for _, want := range []string{
+			"SocksPort unix:" + filepath.Join(trc.dataDirectory, "socks.0.sock"),
+			"SocksPort unix:" + filepath.Join(trc.dataDirectory, "socks.1.sock"),
+			"ControlPort unix:" + filepath.Join(trc.dataDirectory, "control.sock"),
+		} {
+			if !strings.Contains(trc.torrc, want) {
+				t.Fatalf("torrc does not contain %q:\n%s", want, trc.torrc)
+			}
+		}
+	})
+
+	t.Run("Should dial through SOCKS5 proxy on unix socket", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dir, err := os.MkdirTemp("", "tornado-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dir)
+		})
+
+		addr, requests := newTestSOCKS5Server(t, "unix", filepath.Join(dir, "socks.sock"), 0x00)
+
+		prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		conn, err := prx.DialContext(context.Background(), "tcp", "example.com:80")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+		defer conn.Close()
+
+		if req := <-requests; req.host != "example.com" || req.port != 80 {
+			t.Fatalf("unexpected request %+v", req)
+		}
+	})
+}
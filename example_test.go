@@ -47,3 +47,55 @@ func ExampleNewProxy() {
 
 	log.Println(string(text))
 }
+
+func ExampleProxy_ListenOnion() {
+	const proxyServerStartupTimeout = 15 * time.Second
+
+	ctx, done := context.WithTimeout(context.Background(), proxyServerStartupTimeout)
+	defer done()
+
+	prx, err := tornado.NewProxy(ctx)
+	if err != nil {
+		log.Panicln("failed to create new instance of proxy:", err)
+	}
+	defer prx.Close()
+
+	srv, err := prx.ListenOnion(ctx, 80)
+	if err != nil {
+		log.Panicln("failed to publish onion service:", err)
+	}
+	defer srv.Close()
+
+	log.Println("serving on", srv.Addr())
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("Hello, tor!"))
+	})
+
+	if err := http.Serve(srv, handler); err != nil {
+		log.Panicln("failed to serve http:", err)
+	}
+}
+
+func ExampleProxy_HTTPClient() {
+	const proxyServerStartupTimeout = 15 * time.Second
+
+	ctx, done := context.WithTimeout(context.Background(), proxyServerStartupTimeout)
+	defer done()
+
+	prx, err := tornado.NewProxy(ctx)
+	if err != nil {
+		log.Panicln("failed to create new instance of proxy:", err)
+	}
+	defer prx.Close()
+
+	httpcli := prx.HTTPClient(tornado.WithRequestIsolation())
+
+	resp, err := httpcli.Get("https://check.torproject.org/api/ip")
+	if err != nil {
+		log.Panicln("failed to execute http request to tor project api:", err)
+	}
+	defer resp.Body.Close()
+
+	log.Println(resp.Status)
+}
@@ -7,11 +7,32 @@ package tornado
 import (
 	"context"
 	"net"
+	"slices"
 )
 
 // NewFloatingProxy creates new instance of FloatingProxy.
-func NewFloatingProxy(pool *Pool) *FloatingProxy {
-	return &FloatingProxy{pool: pool}
+//
+// Only the options configuring the FloatingProxy are used, such as
+// WithRetryPolicy and WithBalancer, others are ignored. If the retry policy
+// or the balancer is not set, the one of the pool is used.
+func NewFloatingProxy(pool *Pool, ops ...Option) *FloatingProxy {
+	var state options
+
+	for _, option := range ops {
+		option.apply(&state)
+	}
+
+	retryPolicy := state.retryPolicy
+	if retryPolicy == nil {
+		retryPolicy = pool.retryPolicy
+	}
+
+	balancer := state.balancer
+	if balancer == nil {
+		balancer = pool.balancer
+	}
+
+	return &FloatingProxy{pool: pool, retryPolicy: retryPolicy, balancer: balancer}
 }
 
 // A FloatingProxy is an abstraction for making it easy to create connections
@@ -21,7 +42,9 @@ func NewFloatingProxy(pool *Pool) *FloatingProxy {
 // interaction can keep the connection open for different requests while using
 // one common Proxy chain, for example http.Client with http.DefaultTransport.
 type FloatingProxy struct {
-	pool *Pool
+	pool        *Pool
+	retryPolicy *RetryPolicy
+	balancer    Balancer
 }
 
 // Dial connects to the address on the named network.
@@ -38,6 +61,13 @@ func (p *FloatingProxy) Dial(network, address string) (c net.Conn, err error) {
 // DialContext connects to the address on the named network using
 // the provided context.
 //
+// The isolation key set by WithIsolationKey is respected, see
+// Proxy.DialContext. If the retry policy is set, the dial refused by tor
+// is retried with the next proxy of the pool, see WithRetryPolicy.
+//
+// The proxy of the pool is chosen by the balancer set by WithBalancer,
+// if any.
+//
 // See func Dial of the net package of standard library for a
 // description of the network and address parameters.
 func (p *FloatingProxy) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
@@ -45,9 +75,68 @@ func (p *FloatingProxy) DialContext(ctx context.Context, network, address string
 		panic("tornado: nil context")
 	}
 
-	prx := p.pool.Get()
-	conn, err := prx.DialContext(ctx, network, address)
-	p.pool.Put(prx)
+	if p.balancer != nil {
+		return p.dialBalanced(ctx, network, address)
+	}
+
+	dial := func() (*Proxy, net.Conn, error) {
+		prx, err := p.pool.GetContext(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The proxy is returned to the end of the pool queue, so the retry
+		// is made with the next proxy of the pool.
+		conn, err := prx.dialTracked(ctx, network, address)
+		// The proxy instance is obtained from the same pool, so it can't
+		// overflow the pool or be foreign to it.
+		_ = p.pool.Put(prx)
+
+		return prx, conn, err
+	}
+
+	return retryDial(ctx, p.retryPolicy, dial)
+}
+
+// dialBalanced dials with the proxy chosen by the balancer, the retry is
+// made with the proxy other than the failed one.
+func (p *FloatingProxy) dialBalanced(ctx context.Context, network, address string) (net.Conn, error) {
+	if p.pool.life.ended() {
+		return nil, ErrPoolClosed
+	}
+
+	var last *Proxy
+
+	dial := func() (*Proxy, net.Conn, error) {
+		last = p.nextProxy(last)
+		conn, err := last.dialTracked(ctx, network, address)
+
+		return last, conn, err
+	}
+
+	return retryDial(ctx, p.retryPolicy, dial)
+}
+
+// nextProxy returns the proxy chosen by the balancer, other than failed if
+// the pool has other proxies. The balancer always gets all proxies of
+// the pool, so its state, such as the weights of the proxies, is kept.
+func (p *FloatingProxy) nextProxy(failed *Proxy) *Proxy {
+	proxies := p.pool.proxies
+
+	prx := p.balancer.Next(proxies)
+	if failed == nil || len(proxies) == 1 {
+		return prx
+	}
+
+	for i := 1; prx == failed && i < len(proxies); i++ {
+		prx = p.balancer.Next(proxies)
+	}
+
+	if prx == failed {
+		// The balancer insists on the failed proxy, e.g. Random is
+		// unlucky, so the next proxy of the pool is used.
+		prx = proxies[(slices.Index(proxies, failed)+1)%len(proxies)]
+	}
 
-	return conn, err
+	return prx
 }
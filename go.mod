@@ -2,4 +2,7 @@ module github.com/xorcare/tornado
 
 go 1.24.2
 
-require golang.org/x/net v0.50.0
+require (
+	filippo.io/edwards25519 v1.2.0
+	golang.org/x/net v0.50.0
+)
@@ -0,0 +1,214 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultHTTPDialTimeout is long enough for tor to build a new circuit
+	// and to connect to an onion service.
+	defaultHTTPDialTimeout     = 2 * time.Minute
+	defaultHTTPTLSTimeout      = 30 * time.Second
+	defaultHTTPIdleConnTimeout = 90 * time.Second
+)
+
+type httpOptions struct {
+	insecureOnionTLS bool
+	requestIsolation bool
+}
+
+// HTTPOption is an abstraction on the options of the HTTP clients and
+// transports created by Proxy.HTTPClient, Pool.HTTPClient and
+// FloatingProxy.Transport.
+type HTTPOption interface {
+	apply(*httpOptions)
+}
+
+type httpOptionFunc func(*httpOptions)
+
+func (f httpOptionFunc) apply(optionState *httpOptions) {
+	f(optionState)
+}
+
+// WithInsecureOnionTLS disables the verification of TLS certificates of
+// .onion hosts, which often use self-signed certificates. The onion
+// address is derived from the key of the onion service, so tor already
+// authenticates the service. Certificates of other hosts are verified as
+// usual.
+func WithInsecureOnionTLS() HTTPOption {
+	fun := func(s *httpOptions) {
+		s.insecureOnionTLS = true
+	}
+
+	return httpOptionFunc(fun)
+}
+
+// WithRequestIsolation makes each request use its own circuits, unless
+// the context of the request carries the isolation key set by
+// WithIsolationKey, then the requests with the same key share circuits.
+//
+// Connections are not reused between requests, otherwise requests with
+// different isolation keys would share circuits, so requests become
+// slower.
+func WithRequestIsolation() HTTPOption {
+	fun := func(s *httpOptions) {
+		s.requestIsolation = true
+	}
+
+	return httpOptionFunc(fun)
+}
+
+// HTTPClient returns a new http.Client making requests over tor network
+// through the proxy, see newHTTPTransport for the defaults of its
+// transport. Idle connections of the client are closed when the proxy is
+// closed.
+//
+// The isolation key set by WithIsolationKey is respected only by new
+// connections, keep-alive connections are shared by all requests, use
+// WithRequestIsolation to prevent it.
+func (p *Proxy) HTTPClient(ops ...HTTPOption) *http.Client {
+	return &http.Client{Transport: newHTTPTransport(p.DialContext, p.life, ops)}
+}
+
+// HTTPClient returns a new http.Client making requests over tor network
+// through the proxies of the pool, see FloatingProxy.Transport.
+func (p *Pool) HTTPClient(ops ...HTTPOption) *http.Client {
+	return &http.Client{Transport: NewFloatingProxy(p).Transport(ops...)}
+}
+
+// Transport returns a new http.Transport making connections over tor
+// network through the proxies of the pool, each new connection may use
+// a different proxy. Idle connections of the transport are closed when
+// the pool is closed, see Proxy.HTTPClient for the details.
+func (p *FloatingProxy) Transport(ops ...HTTPOption) *http.Transport {
+	return newHTTPTransport(p.DialContext, p.pool.life, ops)
+}
+
+// newHTTPTransport returns a new http.Transport with the defaults suitable
+// for tor: the long dial and TLS handshake timeouts, because building
+// circuits is slow, and HTTP/2 enabled.
+func newHTTPTransport(dial func(ctx context.Context, network, address string) (net.Conn, error),
+	life *lifetime, ops []HTTPOption,
+) *http.Transport {
+	var state httpOptions
+
+	for _, option := range ops {
+		option.apply(&state)
+	}
+
+	dialContext := func(ctx context.Context, network, address string) (net.Conn, error) {
+		ctx, done := context.WithTimeout(ctx, defaultHTTPDialTimeout)
+		defer done()
+
+		if state.requestIsolation && isolationAuth(ctx) == nil {
+			ctx = WithIsolationKey(ctx, rand.Text())
+		}
+
+		return dial(ctx, network, address)
+	}
+
+	transport := &http.Transport{
+		DialContext:           dialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       defaultHTTPIdleConnTimeout,
+		TLSHandshakeTimeout:   defaultHTTPTLSTimeout,
+		ExpectContinueTimeout: time.Second,
+		DisableKeepAlives:     state.requestIsolation,
+	}
+
+	if state.insecureOnionTLS {
+		transport.DialTLSContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialInsecureOnionTLS(ctx, dialContext, network, address)
+		}
+	}
+
+	life.addTransport(transport)
+
+	return transport
+}
+
+// dialInsecureOnionTLS dials the address and makes the TLS handshake,
+// the certificate is verified against the host of the address, unless it
+// is the .onion host, see insecureOnionTLSConfig.
+func dialInsecureOnionTLS(ctx context.Context, dial func(ctx context.Context, network, address string) (net.Conn, error),
+	network, address string,
+) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done := context.WithTimeout(ctx, defaultHTTPTLSTimeout)
+	defer done()
+
+	tlsConn := tls.Client(conn, insecureOnionTLSConfig(host, nil))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// insecureOnionTLSConfig returns the TLS config for the connection to
+// the host, which verifies the certificate of all hosts except .onion
+// ones. The host is verified explicitly, because crypto/tls leaves
+// ServerName empty for IP addresses. If roots is nil, the system roots are
+// used.
+func insecureOnionTLSConfig(host string, roots *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		ServerName: host,
+		NextProtos: []string{"h2", "http/1.1"},
+		// The certificates are verified by VerifyConnection instead.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if isOnionHost(host) {
+				return nil
+			}
+
+			if host == "" {
+				return errors.New("tornado: no host to verify the certificate")
+			}
+
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("tornado: no peer certificates")
+			}
+
+			opts := x509.VerifyOptions{
+				DNSName:       host,
+				Roots:         roots,
+				Intermediates: x509.NewCertPool(),
+			}
+
+			for _, cert := range state.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+
+			_, err := state.PeerCertificates[0].Verify(opts)
+
+			return err
+		},
+	}
+}
+
+func isOnionHost(host string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSuffix(host, ".")), ".onion")
+}
@@ -0,0 +1,406 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestProxy_HTTPClient(t *testing.T) {
+	t.Parallel()
+
+	newProxy := func(t *testing.T) (*Proxy, <-chan socks5Request) {
+		t.Helper()
+
+		addr, requests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+
+		prx, err := openSOCKS5Proxy(addr, nil, nil, func() error { return nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return prx, requests
+	}
+
+	t.Run("Should use tor friendly defaults", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, _ := newProxy(t)
+
+		// act
+		transport := prx.HTTPClient().Transport.(*http.Transport)
+
+		// assert
+		if !transport.ForceAttemptHTTP2 || transport.DisableKeepAlives {
+			t.Fatal("HTTP/2 and keep-alives must be enabled")
+		}
+
+		if transport.TLSHandshakeTimeout != defaultHTTPTLSTimeout {
+			t.Fatalf("got TLS handshake timeout %v, want %v", transport.TLSHandshakeTimeout, defaultHTTPTLSTimeout)
+		}
+	})
+
+	t.Run("Should isolate each connection with request isolation", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, requests := newProxy(t)
+		transport := prx.HTTPClient(WithRequestIsolation()).Transport.(*http.Transport)
+
+		// act
+		for range 2 {
+			conn, err := transport.DialContext(context.Background(), "tcp", "example.com:80")
+			if err != nil {
+				t.Fatal("should not get an error:", err)
+			}
+
+			_ = conn.Close()
+		}
+
+		// assert
+		first, second := <-requests, <-requests
+
+		if first.username == "" || first.username == second.username {
+			t.Fatalf("got usernames %q and %q, want different ones", first.username, second.username)
+		}
+
+		if !transport.DisableKeepAlives {
+			t.Fatal("keep-alives must be disabled")
+		}
+	})
+
+	t.Run("Should keep isolation key of request with request isolation", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, requests := newProxy(t)
+		transport := prx.HTTPClient(WithRequestIsolation()).Transport.(*http.Transport)
+		ctx := WithIsolationKey(context.Background(), "session")
+
+		// act
+		conn, err := transport.DialContext(ctx, "tcp", "example.com:80")
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		_ = conn.Close()
+
+		// assert
+		if req := <-requests; req.username != "session" {
+			t.Fatalf("got username %q, want %q", req.username, "session")
+		}
+	})
+
+	t.Run("Should close idle connections when proxy is closed", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, _ := newProxy(t)
+		client := prx.HTTPClient()
+
+		if got := len(prx.life.transports); got != 1 {
+			t.Fatalf("got %d registered transports, want 1", got)
+		}
+
+		// act
+		_ = prx.Close()
+
+		// assert
+		if got := len(prx.life.transports); got != 0 {
+			t.Fatalf("got %d registered transports after close, want 0", got)
+		}
+
+		runtime.KeepAlive(client)
+	})
+
+	t.Run("Should not keep collected transports", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, _ := newProxy(t)
+
+		for range 10 {
+			_ = prx.HTTPClient()
+		}
+
+		// act
+		runtime.GC()
+		_ = prx.HTTPClient()
+
+		// assert
+		if got := len(prx.life.transports); got != 1 {
+			t.Fatalf("got %d registered transports, want 1", got)
+		}
+	})
+}
+
+func TestHTTPClient_Finalizer(t *testing.T) {
+	t.Parallel()
+
+	// waitFinalized runs the garbage collector until closed is closed by
+	// the finalizer.
+	waitFinalized := func(t *testing.T, closed <-chan struct{}) {
+		t.Helper()
+
+		for range 50 {
+			runtime.GC()
+
+			select {
+			case <-closed:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+
+		t.Fatal("finalizer should close the unreachable instance")
+	}
+
+	t.Run("Should finalize proxy with http client", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		closed := make(chan struct{})
+
+		func() {
+			prx, err := openSOCKS5Proxy(tcpEndpoint(9050), nil, nil, func() error {
+				close(closed)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_ = prx.HTTPClient()
+		}()
+
+		// act & assert
+		waitFinalized(t, closed)
+	})
+
+	t.Run("Should finalize pool with http client", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		closed := make(chan struct{})
+
+		func() {
+			pool := newFreePool(1, nil, func() error {
+				close(closed)
+				return nil
+			})
+
+			prx, err := openSOCKS5Proxy(tcpEndpoint(9050), nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pool.add(prx)
+
+			_ = pool.HTTPClient()
+		}()
+
+		// act & assert
+		waitFinalized(t, closed)
+	})
+}
+
+// newTestCertificateChain returns the pool with the test root and
+// the chain of the leaf certificate for the hosts signed by the root.
+func newTestCertificateChain(t *testing.T, dnsName string, ip netip.Addr) (*x509.CertPool, []*x509.Certificate) {
+	t.Helper()
+
+	newCertificate := func(template, parent *x509.Certificate, key, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return cert
+	}
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "tornado test root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	root := newCertificate(rootTemplate, rootTemplate, rootKey, rootKey)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		IPAddresses:  []net.IP{ip.AsSlice()},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leaf := newCertificate(leafTemplate, root, leafKey, rootKey)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	return roots, []*x509.Certificate{leaf, root}
+}
+
+func Test_insecureOnionTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	roots, chain := newTestCertificateChain(t, "example.com", netip.MustParseAddr("192.0.2.1"))
+
+	tests := []struct {
+		name    string
+		host    string
+		certs   []*x509.Certificate
+		wantErr bool
+	}{
+		{name: "Should skip verification of onion host", host: testOnionAddress},
+		{name: "Should accept certificate of host", host: "example.com", certs: chain},
+		{name: "Should accept certificate of IP address", host: "192.0.2.1", certs: chain},
+		{name: "Should reject certificate of other host", host: "example.org", certs: chain, wantErr: true},
+		{name: "Should reject certificate of other IP address", host: "203.0.113.5", certs: chain, wantErr: true},
+		{name: "Should reject certificate without host", host: "", certs: chain, wantErr: true},
+		{name: "Should reject missing certificates", host: "example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			config := insecureOnionTLSConfig(tt.host, roots)
+
+			// act
+			err := config.VerifyConnection(tls.ConnectionState{PeerCertificates: tt.certs})
+
+			// assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Should reject certificate of untrusted root", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		config := insecureOnionTLSConfig("example.com", x509.NewCertPool())
+
+		// act
+		err := config.VerifyConnection(tls.ConnectionState{PeerCertificates: chain})
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
+
+func TestWithInsecureOnionTLS(t *testing.T) {
+	t.Parallel()
+
+	newTestClient := func(t *testing.T) *http.Client {
+		t.Helper()
+
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		}))
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+		t.Cleanup(srv.Close)
+
+		// All hosts are served by the test server, which certificate is
+		// issued for "example.com" and 127.0.0.1 by the untrusted root.
+		dial := func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, srv.Listener.Addr().String())
+		}
+
+		transport := newHTTPTransport(dial, newLifetime(), []HTTPOption{WithInsecureOnionTLS()})
+		t.Cleanup(transport.CloseIdleConnections)
+
+		return &http.Client{Transport: transport}
+	}
+
+	t.Run("Should skip verification of onion host using HTTP/2", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		client := newTestClient(t)
+
+		// act
+		resp, err := client.Get("https://" + testOnionAddress + "/")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(body); got != "HTTP/2.0" {
+			t.Fatalf("got protocol %q, want %q", got, "HTTP/2.0")
+		}
+	})
+
+	t.Run("Should reject certificate of untrusted root", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		client := newTestClient(t)
+
+		// act
+		_, err := client.Get("https://example.com/")
+
+		// assert
+		var unknownAuthority x509.UnknownAuthorityError
+		if !errors.As(err, &unknownAuthority) {
+			t.Fatalf("got error %v, want %T", err, unknownAuthority)
+		}
+	})
+
+	t.Run("Should verify IP address of host", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		client := newTestClient(t)
+
+		// act
+		_, err := client.Get("https://203.0.113.5/")
+
+		// assert
+		var hostnameErr x509.HostnameError
+		if !errors.As(err, &hostnameErr) {
+			t.Fatalf("got error %v, want %T", err, hostnameErr)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/xorcare/tornado/internal/freeport"
+)
+
+// ErrNoHTTPTunnel is returned when an operation requires the HTTP CONNECT
+// tunnel port of the proxy, but it is not enabled, see WithHTTPTunnel.
+var ErrNoHTTPTunnel = errors.New("tornado: http tunnel is not enabled")
+
+// WithHTTPTunnel makes the tor demon listen on the HTTPTunnelPort for each
+// proxy alongside the SocksPort, so the programs supporting only HTTP
+// proxies can make connections over tor network through the HTTP CONNECT
+// method, see Proxy.HTTPTunnelAddr.
+//
+// The HTTP tunnel ports always listen on the loopback TCP addresses, even
+// if WithUnixSockets is used. Keep in mind that any local process can use
+// them.
+func WithHTTPTunnel() Option {
+	fun := func(s *options) {
+		s.httpTunnel = true
+	}
+
+	return optionFunc(fun)
+}
+
+// httpTunnelEndpoints returns the addresses of HTTP tunnel ports of the tor
+// demon on free TCP ports.
+func httpTunnelEndpoints(number int) ([]endpoint, error) {
+	ports, err := freeport.Much(number)
+	if err != nil {
+		const format = "cannot get free ports for http tunnel: %v"
+		return nil, fmt.Errorf(format, err)
+	}
+
+	addrs := make([]endpoint, 0, number)
+
+	for _, port := range ports {
+		addrs = append(addrs, tcpEndpoint(port))
+	}
+
+	return addrs, nil
+}
+
+// HTTPTunnelAddr returns the "host:port" address of the HTTP CONNECT
+// tunnel port of the tor demon serving the proxy, it is empty if the HTTP
+// tunnel is not enabled, see WithHTTPTunnel.
+//
+// The connections made through the tunnel port bypass the Proxy, so they
+// are not affected by Close, Shutdown and WithIsolationKey.
+func (p *Proxy) HTTPTunnelAddr() string {
+	return p.httpTunnel.address
+}
+
+// HTTPTunnelTransport returns a new http.Transport, which makes requests
+// through the HTTP CONNECT tunnel port of the proxy by setting
+// the Transport.Proxy field, other fields are copied from
+// http.DefaultTransport. It returns ErrNoHTTPTunnel if the HTTP tunnel is
+// not enabled, see WithHTTPTunnel.
+func (p *Proxy) HTTPTunnelTransport() (*http.Transport, error) {
+	if p.httpTunnel.address == "" {
+		return nil, ErrNoHTTPTunnel
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(&url.URL{Scheme: "http", Host: p.httpTunnel.address})
+
+	return transport, nil
+}
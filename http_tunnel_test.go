@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithHTTPTunnel(t *testing.T) {
+	t.Parallel()
+	t.Run("Should add http tunnel port for each proxy", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		state := options{numberOfProxy: 2}
+		WithHTTPTunnel().apply(&state)
+
+		// act
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = trc.cleanup()
+		})
+
+		// assert
+		if len(trc.httpTunnelAddress) != 2 {
+			t.Fatalf("got %d http tunnel addresses, want 2", len(trc.httpTunnelAddress))
+		}
+
+		for _, addr := range trc.httpTunnelAddress {
+			if !strings.Contains(trc.torrc, "HTTPTunnelPort "+addr.address+"\n") {
+				t.Fatalf("torrc does not contain http tunnel port %s:\n%s", addr.address, trc.torrc)
+			}
+		}
+	})
+}
+
+func TestProxy_HTTPTunnelTransport(t *testing.T) {
+	t.Parallel()
+	t.Run("Should use http tunnel as proxy of transport", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx := &Proxy{httpTunnel: tcpEndpoint(8118)}
+		req, _ := http.NewRequest(http.MethodGet, "https://check.torproject.org", nil)
+
+		// act
+		transport, err := prx.HTTPTunnelTransport()
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		// assert
+		proxyURL, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if got := proxyURL.String(); got != "http://127.0.0.1:8118" {
+			t.Fatalf("got proxy %q, want %q", got, "http://127.0.0.1:8118")
+		}
+
+		if got := prx.HTTPTunnelAddr(); got != "127.0.0.1:8118" {
+			t.Fatalf("got address %q, want %q", got, "127.0.0.1:8118")
+		}
+	})
+
+	t.Run("Should return error when http tunnel is not enabled", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx := &Proxy{}
+
+		// act
+		_, err := prx.HTTPTunnelTransport()
+
+		// assert
+		if !errors.Is(err, ErrNoHTTPTunnel) {
+			t.Fatalf("ErrNoHTTPTunnel error was expected, but got another one: %v", err)
+		}
+	})
+}
@@ -42,3 +42,19 @@ func Much(count int) ([]int, error) {
 
 	return ports, nil
 }
+
+// UDP returns a free UDP port on the loopback address.
+func UDP() (int, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		const format = "freeport: cannot start listen udp: %v"
+		return 0, fmt.Errorf(format, err)
+	}
+
+	if err := conn.Close(); err != nil {
+		const format = "freeport: cannot close udp listener: %v"
+		return 0, fmt.Errorf(format, err)
+	}
+
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/net/proxy"
+)
+
+type isolationKeyContextKey struct{}
+
+// WithIsolationKey returns a copy of the parent context carrying
+// the isolation key. Connections made by Proxy and FloatingProxy using
+// contexts with different isolation keys never share circuits, so each
+// logical session can get its own exit without additional proxies.
+//
+// The key is passed to tor as the SOCKS5 username and password, and tor
+// isolates streams by them, see the IsolateSOCKSAuth flag of SocksPort,
+// which is enabled by default.
+func WithIsolationKey(parent context.Context, key string) context.Context {
+	if parent == nil {
+		panic("tornado: nil context")
+	}
+
+	return context.WithValue(parent, isolationKeyContextKey{}, key)
+}
+
+// isolationAuth returns SOCKS5 credentials for the isolation key from
+// the context, or nil if there is no key.
+func isolationAuth(ctx context.Context) *proxy.Auth {
+	key, ok := ctx.Value(isolationKeyContextKey{}).(string)
+	if !ok {
+		return nil
+	}
+
+	// Both username and password must be from 1 to 255 bytes long.
+	if key == "" || len(key) > 255 {
+		sum := sha256.Sum256([]byte(key))
+		key = hex.EncodeToString(sum[:])
+	}
+
+	return &proxy.Auth{User: key, Password: key}
+}
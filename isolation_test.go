@@ -0,0 +1,67 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestWithIsolationKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantUser string
+	}{
+		{
+			name:     "Should not authenticate without isolation key",
+			ctx:      context.Background(),
+			wantUser: "",
+		},
+		{
+			name:     "Should pass isolation key as SOCKS5 username",
+			ctx:      WithIsolationKey(context.Background(), "session-1"),
+			wantUser: "session-1",
+		},
+		{
+			name: "Should hash too long isolation key",
+			ctx:  WithIsolationKey(context.Background(), strings.Repeat("x", 256)),
+			wantUser: func() string {
+				sum := sha256.Sum256([]byte(strings.Repeat("x", 256)))
+				return hex.EncodeToString(sum[:])
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			addr, requests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+
+			prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			conn, err := prx.DialContext(tt.ctx, "tcp", "example.com:80")
+			if err != nil {
+				t.Fatal("should not get an error:", err)
+			}
+			defer conn.Close()
+
+			// assert
+			if req := <-requests; req.username != tt.wantUser || req.password != tt.wantUser {
+				t.Fatalf("got credentials %q:%q, want %q", req.username, req.password, tt.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"weak"
+)
+
+// lifetime tracks whether the Proxy or the Pool is closed and its active
+// connections, it is shared between the Pool and its proxies.
+//
+// The lifetime must not reference the Pool or the Proxy, otherwise it
+// creates a reference cycle, and their finalizers will never run.
+type lifetime struct {
+	done  chan struct{}
+	once  sync.Once
+	conns connTracker
+
+	// transports are the HTTP transports of the Proxy or the Pool, their
+	// idle connections are closed when the lifetime is ended. They are
+	// referenced weakly, because they reference the Proxy or the Pool.
+	mu         sync.Mutex
+	transports []weak.Pointer[http.Transport]
+}
+
+func newLifetime() *lifetime {
+	return &lifetime{
+		done: make(chan struct{}),
+	}
+}
+
+// end marks the lifetime as ended, it is safe to call it multiple times.
+func (l *lifetime) end() {
+	l.once.Do(func() {
+		l.mu.Lock()
+		close(l.done)
+		transports := l.transports
+		l.transports = nil
+		l.mu.Unlock()
+
+		for _, ptr := range transports {
+			if transport := ptr.Value(); transport != nil {
+				transport.CloseIdleConnections()
+			}
+		}
+	})
+}
+
+// addTransport arranges to close idle connections of the transport when
+// the lifetime is ended, or closes them now if it is already ended.
+func (l *lifetime) addTransport(transport *http.Transport) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ended() {
+		transport.CloseIdleConnections()
+		return
+	}
+
+	// The collected transports are dropped, so the list doesn't grow
+	// with each call of HTTPClient.
+	l.transports = slices.DeleteFunc(l.transports, func(ptr weak.Pointer[http.Transport]) bool {
+		return ptr.Value() == nil
+	})
+
+	l.transports = append(l.transports, weak.Make(transport))
+}
+
+// ended reports whether the lifetime is ended.
+func (l *lifetime) ended() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// connTracker counts the active connections, including the ones being
+// dialed, so Shutdown can wait until they are closed.
+type connTracker struct {
+	mu     sync.Mutex
+	active int
+	// idle is closed when the number of active connections drops to zero.
+	idle chan struct{}
+}
+
+// add registers a new active connection.
+func (t *connTracker) add() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.active == 0 {
+		t.idle = make(chan struct{})
+	}
+
+	t.active++
+}
+
+// done unregisters the active connection.
+func (t *connTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active--
+
+	if t.active == 0 {
+		close(t.idle)
+	}
+}
+
+// count returns the number of active connections.
+func (t *connTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.active
+}
+
+// wait waits until there are no active connections or the context is done.
+func (t *connTracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	if t.active == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// trackedConn is the connection registered in the connTracker, it is
+// unregistered when the connection is closed.
+type trackedConn struct {
+	net.Conn
+
+	conns *connTracker
+	// active is the counter of the active connections of the proxy.
+	active    *atomic.Int64
+	closeOnce sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		c.active.Add(-1)
+		c.conns.done()
+	})
+
+	return err
+}
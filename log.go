@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+// LevelTorInfo is the level of the records of the tor log with the info
+// severity, which is more verbose than notice, but less verbose than
+// debug. The notice severity of tor corresponds to slog.LevelInfo.
+const LevelTorInfo = slog.LevelDebug + 2
+
+var (
+	// Matches lines like:
+	//	Oct 18 12:00:00.000 [notice] Bootstrapped 5% (conn): Connecting to a relay
+	torLogRegexp = regexp.MustCompile(`^(\w{3} +\d{1,2} \d{2}:\d{2}:\d{2}(?:\.\d{3})?) \[(\w+)\] (.*)$`)
+
+	// Matches the function name at the beginning of the message, tor adds
+	// it to the messages with the info and debug severities.
+	torLogFunctionRegexp = regexp.MustCompile(`^(\w+)\(\): (.*)$`)
+)
+
+// WithLogger allows to receive the log of the tor demon as records of
+// the logger for the whole lifetime of the tor demon.
+//
+// The records have the time and the level of the tor log messages, and
+// the "tor" group with the original severity and, if present, the name of
+// the function which wrote the message.
+func WithLogger(logger *slog.Logger) Option {
+	fun := func(s *options) {
+		s.logger = logger
+	}
+
+	return optionFunc(fun)
+}
+
+// WithLogLevel allows to choose the minimum level of the records of the tor
+// log passed to the logger specified by WithLogger, the default level is
+// slog.LevelInfo, which corresponds to the notice severity of tor.
+//
+// The level also chooses the severity of the Log option of torrc, use
+// slog.LevelDebug for the debug severity and LevelTorInfo for the info
+// severity. Tornado needs the messages with the notice severity to detect
+// the end of the bootstrapping, so tor always writes them, the levels above
+// slog.LevelInfo only filter out the records.
+func WithLogLevel(level slog.Level) Option {
+	fun := func(s *options) {
+		s.logLevel = level
+	}
+
+	return optionFunc(fun)
+}
+
+// torLogSeverity returns the minimum severity of the tor log written to
+// stderr, it is never above notice.
+func (s options) torLogSeverity() string {
+	switch {
+	case s.logLevel <= slog.LevelDebug:
+		return "debug"
+	case s.logLevel <= LevelTorInfo:
+		return "info"
+	default:
+		return "notice"
+	}
+}
+
+// torLogLevel returns the level of the record for the tor log severity.
+func torLogLevel(severity string) slog.Level {
+	switch severity {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return LevelTorInfo
+	case "warn":
+		return slog.LevelWarn
+	case "err":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// parseTorLogLine parses the line of the tor log into the record, lines
+// in an unknown format are passed as is with slog.LevelInfo.
+func parseTorLogLine(line string, now time.Time) slog.Record {
+	match := torLogRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return slog.NewRecord(now, slog.LevelInfo, line, 0)
+	}
+
+	stamp, severity, message := match[1], match[2], match[3]
+
+	// Tor writes the time in the local time zone without the year.
+	when, err := time.ParseInLocation("Jan 2 15:04:05.000", stamp, now.Location())
+	if err != nil {
+		when, err = time.ParseInLocation("Jan 2 15:04:05", stamp, now.Location())
+	}
+
+	if err == nil {
+		when = when.AddDate(now.Year(), 0, 0)
+		// The message written on December 31 is read on January 1.
+		if when.After(now.Add(24 * time.Hour)) {
+			when = when.AddDate(-1, 0, 0)
+		}
+	} else {
+		when = now
+	}
+
+	attrs := []any{slog.String("severity", severity)}
+
+	if match := torLogFunctionRegexp.FindStringSubmatch(message); match != nil {
+		attrs = append(attrs, slog.String("function", match[1]))
+		message = match[2]
+	}
+
+	rec := slog.NewRecord(when, torLogLevel(severity), message, 0)
+	rec.AddAttrs(slog.Group("tor", attrs...))
+
+	return rec
+}
+
+// logTorLine passes the line of the tor log to the logger, if it is set.
+func (s options) logTorLine(line string) {
+	if s.logger == nil {
+		return
+	}
+
+	rec := parseTorLogLine(line, time.Now())
+	if rec.Level < s.logLevel {
+		return
+	}
+
+	ctx := context.Background()
+
+	if handler := s.logger.Handler(); handler.Enabled(ctx, rec.Level) {
+		_ = handler.Handle(ctx, rec)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// recordHandler is the slog.Handler sending the records to the channel.
+type recordHandler chan slog.Record
+
+func (h recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h recordHandler) Handle(_ context.Context, rec slog.Record) error {
+	h <- rec
+	return nil
+}
+
+func (h recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h recordHandler) WithGroup(string) slog.Handler { return h }
+
+func Test_parseTorLogLine(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		line        string
+		wantTime    time.Time
+		wantLevel   slog.Level
+		wantMessage string
+		wantAttrs   string
+	}{
+		{
+			name:        "Should parse notice line",
+			line:        "Oct 18 08:36:59.123 [notice] Bootstrapped 5% (conn): Connecting to a relay",
+			wantTime:    time.Date(2026, time.October, 18, 8, 36, 59, 123e6, time.UTC),
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Bootstrapped 5% (conn): Connecting to a relay",
+			wantAttrs:   "tor=[severity=notice]",
+		},
+		{
+			name:        "Should parse function name of info line",
+			line:        "Oct 18 08:36:59.000 [info] circuit_build_failed(): Our circuit died.",
+			wantTime:    time.Date(2026, time.October, 18, 8, 36, 59, 0, time.UTC),
+			wantLevel:   LevelTorInfo,
+			wantMessage: "Our circuit died.",
+			wantAttrs:   "tor=[severity=info function=circuit_build_failed]",
+		},
+		{
+			name:        "Should parse warn line of previous year",
+			line:        "Dec 31 23:59:59.000 [warn] Clock skew detected",
+			wantTime:    time.Date(2025, time.December, 31, 23, 59, 59, 0, time.UTC),
+			wantLevel:   slog.LevelWarn,
+			wantMessage: "Clock skew detected",
+			wantAttrs:   "tor=[severity=warn]",
+		},
+		{
+			name:        "Should pass unknown line as is",
+			line:        "Tor can't help you if you use it wrong!",
+			wantTime:    now,
+			wantLevel:   slog.LevelInfo,
+			wantMessage: "Tor can't help you if you use it wrong!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := parseTorLogLine(tt.line, now)
+
+			if !rec.Time.Equal(tt.wantTime) {
+				t.Fatalf("got time %v, want %v", rec.Time, tt.wantTime)
+			}
+
+			if rec.Level != tt.wantLevel {
+				t.Fatalf("got level %v, want %v", rec.Level, tt.wantLevel)
+			}
+
+			if rec.Message != tt.wantMessage {
+				t.Fatalf("got message %q, want %q", rec.Message, tt.wantMessage)
+			}
+
+			var attrs string
+
+			rec.Attrs(func(attr slog.Attr) bool {
+				attrs = attr.String()
+				return true
+			})
+
+			if attrs != tt.wantAttrs {
+				t.Fatalf("got attrs %q, want %q", attrs, tt.wantAttrs)
+			}
+		})
+	}
+}
+
+func TestWithLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		want  string
+	}{
+		{name: "Should use debug severity", level: slog.LevelDebug, want: "debug"},
+		{name: "Should use info severity", level: LevelTorInfo, want: "info"},
+		{name: "Should use notice severity by default", level: slog.LevelInfo, want: "notice"},
+		{name: "Should not use severity above notice", level: slog.LevelError, want: "notice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var state options
+
+			WithLogLevel(tt.level).apply(&state)
+
+			if got := state.torLogSeverity(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+	t.Run("Should pass log of tor demon after bootstrapping", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		records := make(recordHandler, 16)
+		binary := newFakeTorDemonBinary(t,
+			"Oct 18 00:00:00.000 [notice] Bootstrapped 100% (done): Done",
+			"Oct 18 00:00:01.000 [warn] Socks version 71 not recognized.",
+		)
+		state := options{
+			torBinary: binary,
+			logger:    slog.New(records),
+			logLevel:  slog.LevelWarn,
+		}
+		trc := torrc{dataDirectory: t.TempDir()}
+		trc.filename = filepath.Join(trc.dataDirectory, "torrc")
+
+		// act
+		cmd, err := launchBackgroundTorDemon(context.Background(), trc, state)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tor := startDaemon(cmd, trc, state)
+		t.Cleanup(func() {
+			_ = tor.shutdown()
+		})
+
+		// assert
+		rec := <-records
+
+		if rec.Level != slog.LevelWarn || rec.Message != "Socks version 71 not recognized." {
+			t.Fatalf("unexpected record %v %q", rec.Level, rec.Message)
+		}
+	})
+}
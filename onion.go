@@ -0,0 +1,226 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"crypto/ecdh"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// An OnionAddr represents the address of an onion service.
+type OnionAddr struct {
+	// ServiceID is the onion address without the ".onion" suffix.
+	ServiceID string
+	// Port is the virtual port of the onion service.
+	Port int
+}
+
+// Network returns the address's network name, "onion".
+func (a *OnionAddr) Network() string {
+	return "onion"
+}
+
+// Hostname returns the host name of the onion service, e.g.
+// "xxx.onion".
+func (a *OnionAddr) Hostname() string {
+	return a.ServiceID + ".onion"
+}
+
+func (a *OnionAddr) String() string {
+	return net.JoinHostPort(a.Hostname(), strconv.Itoa(a.Port))
+}
+
+type onionOptions struct {
+	key               *OnionKey
+	authorizedClients []*ecdh.PublicKey
+}
+
+// OnionOption is an abstraction on the options of an onion service.
+type OnionOption interface {
+	apply(*onionOptions)
+}
+
+type onionOptionFunc func(*onionOptions)
+
+func (f onionOptionFunc) apply(optionState *onionOptions) {
+	f(optionState)
+}
+
+// WithOnionKey allows to publish the onion service using the existing key,
+// so the service keeps the same onion address across restarts.
+func WithOnionKey(key *OnionKey) OnionOption {
+	fun := func(s *onionOptions) {
+		s.key = key
+	}
+
+	return onionOptionFunc(fun)
+}
+
+// An OnionService is an ephemeral v3 onion service published by the tor
+// demon. OnionService implements net.Listener, so it can be used to serve
+// connections coming from the tor network, for example by http.Server.
+//
+// The onion service exists as long as the OnionService is open, and
+// the tor demon and the Controller that published it are running.
+type OnionService struct {
+	listener net.Listener
+	ctrl     *Controller
+	addr     *OnionAddr
+	key      *OnionKey
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// ListenOnion publishes a new ephemeral v3 onion service on the virtual
+// port and returns the listener accepting its connections.
+//
+// If the key is not specified via WithOnionKey, tor generates a new one,
+// it is available via OnionService.Key.
+//
+// Tor publishes descriptors of the service in the background, so it may
+// take some time before the service becomes reachable.
+func (c *Controller) ListenOnion(ctx context.Context, port int, ops ...OnionOption) (*OnionService, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if port < 1 || port > 65535 {
+		const format = "tornado: invalid onion service port %d"
+		return nil, fmt.Errorf(format, port)
+	}
+
+	var state onionOptions
+
+	for _, option := range ops {
+		option.apply(&state)
+	}
+
+	keyArg := "NEW:ED25519-V3"
+
+	if state.key != nil {
+		text, err := state.key.MarshalText()
+		if err != nil {
+			const format = "cannot marshal onion key: %v"
+			return nil, fmt.Errorf(format, err)
+		}
+
+		keyArg = string(text)
+	}
+
+	var lc net.ListenConfig
+
+	listener, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		const format = "cannot listen local target of onion service: %v"
+		return nil, fmt.Errorf(format, err)
+	}
+
+	var flags, clientAuth string
+
+	if len(state.authorizedClients) > 0 {
+		flags = " Flags=V3Auth"
+
+		for _, pub := range state.authorizedClients {
+			clientAuth += " ClientAuthV3=" + onionBase32.EncodeToString(pub.Bytes())
+		}
+	}
+
+	const command = "ADD_ONION %s%s Port=%d,%s%s"
+
+	rpl, err := c.do(ctx, command, keyArg, flags, port, listener.Addr(), clientAuth)
+	if err != nil {
+		_ = listener.Close()
+
+		const format = "cannot add onion service: %v"
+
+		return nil, fmt.Errorf(format, err)
+	}
+
+	srv := &OnionService{
+		listener: listener,
+		ctrl:     c,
+		addr:     &OnionAddr{Port: port},
+		key:      state.key,
+	}
+
+	for _, line := range rpl.lines {
+		key, value, _ := strings.Cut(line, "=")
+
+		switch key {
+		case "ServiceID":
+			srv.addr.ServiceID = value
+		case "PrivateKey":
+			srv.key = &OnionKey{}
+			if err := srv.key.UnmarshalText([]byte(value)); err != nil {
+				_ = srv.Close()
+
+				const format = "cannot parse onion key generated by tor: %v"
+
+				return nil, fmt.Errorf(format, err)
+			}
+		}
+	}
+
+	if srv.addr.ServiceID == "" {
+		_ = listener.Close()
+
+		const format = "no service id in reply to add onion service: %d"
+
+		return nil, fmt.Errorf(format, rpl.code)
+	}
+
+	return srv, nil
+}
+
+// ListenOnion publishes a new ephemeral v3 onion service on the virtual
+// port using the tor demon serving the proxy, see Controller.ListenOnion.
+func (p *Proxy) ListenOnion(ctx context.Context, port int, ops ...OnionOption) (*OnionService, error) {
+	if p.controller == nil {
+		return nil, ErrNoController
+	}
+
+	return p.controller.ListenOnion(ctx, port, ops...)
+}
+
+// Key returns the secret key of the onion service. Save it to publish
+// the service with the same onion address later using WithOnionKey.
+func (s *OnionService) Key() *OnionKey {
+	return s.key
+}
+
+// Accept waits for and returns the next connection to the onion service.
+func (s *OnionService) Accept() (net.Conn, error) {
+	return s.listener.Accept()
+}
+
+// Addr returns the address of the onion service, it is always *OnionAddr.
+func (s *OnionService) Addr() net.Addr {
+	return s.addr
+}
+
+// Close removes the onion service from the tor network and closes
+// the listener. Any blocked Accept operations will be unblocked and
+// return errors.
+func (s *OnionService) Close() error {
+	s.closeOnce.Do(func() {
+		_, err := s.ctrl.do(context.Background(), "DEL_ONION %s", s.addr.ServiceID)
+		if err != nil {
+			const format = "cannot delete onion service: %v"
+			s.closeErr = fmt.Errorf(format, err)
+		}
+
+		if err := s.listener.Close(); err != nil && s.closeErr == nil {
+			s.closeErr = err
+		}
+	})
+
+	return s.closeErr
+}
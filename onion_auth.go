@@ -0,0 +1,189 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"crypto/ecdh"
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const onionClientAuthPrefix = "descriptor:x25519:"
+
+// GenerateOnionClientKey generates a new x25519 key pair of a client of
+// the onion service with client authorization, using entropy from rand.
+// If rand is nil, crypto/rand.Reader will be used.
+func GenerateOnionClientKey(rand io.Reader) (*ecdh.PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+
+	return ecdh.X25519().GenerateKey(rand)
+}
+
+// FormatAuthorizedClient returns the content of the file in the
+// authorized_clients directory of the onion service for the public key of
+// the client, in the "descriptor:x25519:<base32>" format.
+func FormatAuthorizedClient(pub *ecdh.PublicKey) string {
+	return onionClientAuthPrefix + onionBase32.EncodeToString(pub.Bytes())
+}
+
+// ParseAuthorizedClient parses the public key of the client in the format
+// produced by FormatAuthorizedClient.
+func ParseAuthorizedClient(line string) (*ecdh.PublicKey, error) {
+	blob, ok := strings.CutPrefix(strings.TrimSpace(line), onionClientAuthPrefix)
+	if !ok {
+		const format = "tornado: authorized client must have the %q prefix"
+		return nil, fmt.Errorf(format, onionClientAuthPrefix)
+	}
+
+	raw, err := onionBase32.DecodeString(strings.ToUpper(blob))
+	if err != nil {
+		const format = "tornado: cannot decode authorized client key: %v"
+		return nil, fmt.Errorf(format, err)
+	}
+
+	pub, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		const format = "tornado: invalid authorized client key: %v"
+		return nil, fmt.Errorf(format, err)
+	}
+
+	return pub, nil
+}
+
+// FormatClientAuthPrivate returns the content of the .auth_private file
+// in the ClientOnionAuthDir for the onion service, in the
+// "<onion>:descriptor:x25519:<base32>" format.
+func FormatClientAuthPrivate(onionAddr string, key *ecdh.PrivateKey) (string, error) {
+	if _, err := ParseOnionAddress(onionAddr); err != nil {
+		return "", err
+	}
+
+	id := strings.TrimSuffix(strings.ToLower(onionAddr), ".onion")
+
+	return id + ":" + onionClientAuthPrefix + onionBase32.EncodeToString(key.Bytes()), nil
+}
+
+// ParseClientAuthPrivate parses the onion address and the private key of
+// the client in the format produced by FormatClientAuthPrivate.
+func ParseClientAuthPrivate(line string) (onionAddr string, key *ecdh.PrivateKey, err error) {
+	id, blob, ok := strings.Cut(strings.TrimSpace(line), ":")
+	if !ok {
+		return "", nil, errors.New("tornado: client auth must have the onion address prefix")
+	}
+
+	if _, err := ParseOnionAddress(id); err != nil {
+		return "", nil, err
+	}
+
+	blob, ok = strings.CutPrefix(blob, onionClientAuthPrefix)
+	if !ok {
+		const format = "tornado: client auth must have the %q prefix after the onion address"
+		return "", nil, fmt.Errorf(format, onionClientAuthPrefix)
+	}
+
+	raw, err := onionBase32.DecodeString(strings.ToUpper(blob))
+	if err != nil {
+		const format = "tornado: cannot decode client auth key: %v"
+		return "", nil, fmt.Errorf(format, err)
+	}
+
+	key, err = ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		const format = "tornado: invalid client auth key: %v"
+		return "", nil, fmt.Errorf(format, err)
+	}
+
+	return strings.ToLower(id) + ".onion", key, nil
+}
+
+// WithOnionAuthorizedClients enables v3 client authorization for the onion
+// service, only clients owning the private keys corresponding to the public
+// keys will be able to connect to the service.
+func WithOnionAuthorizedClients(keys ...*ecdh.PublicKey) OnionOption {
+	fun := func(s *onionOptions) {
+		s.authorizedClients = append(s.authorizedClients, keys...)
+	}
+
+	return onionOptionFunc(fun)
+}
+
+// WithOnionClientAuth allows to connect to the onion service with v3 client
+// authorization using the private key of the client.
+func WithOnionClientAuth(onionAddr string, key *ecdh.PrivateKey) Option {
+	fun := func(s *options) {
+		s.onionClientAuth = append(s.onionClientAuth, onionClientAuth{
+			onionAddr: onionAddr,
+			key:       key,
+		})
+	}
+
+	return optionFunc(fun)
+}
+
+type onionClientAuth struct {
+	onionAddr string
+	key       *ecdh.PrivateKey
+}
+
+// writeClientOnionAuthDir writes the keys of the client to the directory,
+// which will be used as ClientOnionAuthDir of tor.
+func writeClientOnionAuthDir(dir string, auths []onionClientAuth) error {
+	// The directory may be left from the previous run in the persistent
+	// data directory, the keys that are no longer used must not be kept.
+	if err := os.RemoveAll(dir); err != nil {
+		const format = "cannot remove client onion auth dir: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		const format = "cannot create client onion auth dir: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	for i, auth := range auths {
+		line, err := FormatClientAuthPrivate(auth.onionAddr, auth.key)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Join(dir, fmt.Sprintf("tornado.%d.auth_private", i))
+
+		if err := os.WriteFile(name, []byte(line+"\n"), 0o600); err != nil {
+			const format = "cannot write client onion auth file: %v"
+			return fmt.Errorf(format, err)
+		}
+	}
+
+	return nil
+}
+
+// AddOnionClientAuth registers the private key of the client for
+// the onion service with v3 client authorization in the running tor demon.
+// The key is not persisted, it is lost when tor exits.
+func (c *Controller) AddOnionClientAuth(ctx context.Context, onionAddr string, key *ecdh.PrivateKey) error {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if _, err := ParseOnionAddress(onionAddr); err != nil {
+		return err
+	}
+
+	id := strings.TrimSuffix(strings.ToLower(onionAddr), ".onion")
+	blob := base64.StdEncoding.EncodeToString(key.Bytes())
+
+	_, err := c.do(ctx, "ONION_CLIENT_AUTH_ADD %s x25519:%s", id, blob)
+
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testOnionAddress = "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion"
+
+func TestParseAuthorizedClient(t *testing.T) {
+	t.Parallel()
+	t.Run("Should restore the public key formatted by FormatAuthorizedClient", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		key, err := GenerateOnionClientKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line := FormatAuthorizedClient(key.PublicKey())
+
+		// act
+		pub, err := ParseAuthorizedClient(line)
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if !strings.HasPrefix(line, "descriptor:x25519:") {
+			t.Fatalf("unexpected format of authorized client %q", line)
+		}
+
+		if !pub.Equal(key.PublicKey()) {
+			t.Fatal("public keys are not equal")
+		}
+	})
+}
+
+func TestParseClientAuthPrivate(t *testing.T) {
+	t.Parallel()
+	t.Run("Should restore the key formatted by FormatClientAuthPrivate", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		key, err := GenerateOnionClientKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line, err := FormatClientAuthPrivate(testOnionAddress, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		addr, got, err := ParseClientAuthPrivate(line)
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if want := strings.TrimSuffix(testOnionAddress, ".onion") + ":descriptor:x25519:"; !strings.HasPrefix(line, want) {
+			t.Fatalf("unexpected format of client auth %q", line)
+		}
+
+		if addr != testOnionAddress {
+			t.Fatalf("got onion address %q, want %q", addr, testOnionAddress)
+		}
+
+		if !got.Equal(key) {
+			t.Fatal("private keys are not equal")
+		}
+	})
+
+	t.Run("Should reject invalid onion address", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		key, err := GenerateOnionClientKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		_, err = FormatClientAuthPrivate("example.onion", key)
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
+
+func TestWithOnionClientAuth(t *testing.T) {
+	t.Parallel()
+	t.Run("Should write the key to ClientOnionAuthDir", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		key, err := GenerateOnionClientKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		state := options{numberOfProxy: 1}
+		WithOnionClientAuth(testOnionAddress, key).apply(&state)
+
+		// act
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = os.RemoveAll(trc.dataDirectory)
+		})
+
+		// assert
+		if !strings.Contains(trc.torrc, "ClientOnionAuthDir "+trc.clientOnionAuthDir) {
+			t.Fatalf("torrc does not contain ClientOnionAuthDir:\n%s", trc.torrc)
+		}
+
+		files, err := filepath.Glob(filepath.Join(trc.clientOnionAuthDir, "*.auth_private"))
+		if err != nil || len(files) != 1 {
+			t.Fatalf("expected a single .auth_private file, got %q, %v", files, err)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	// onionKeyTextPrefix is the key type prefix used by the tor control
+	// protocol for v3 onion service keys.
+	onionKeyTextPrefix = "ED25519-V3:"
+
+	// onionKeyFileHeader is the header of the hs_ed25519_secret_key file
+	// written by tor to the HiddenServiceDir.
+	onionKeyFileHeader = "== ed25519v1-secret: type0 ==\x00\x00\x00"
+
+	onionExpandedKeySize = 64
+	onionAddressVersion  = 0x03
+)
+
+var onionBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// An OnionKey is the secret key of a v3 onion service, the onion address
+// of the service is derived from it.
+//
+// Tor stores the key in the expanded form, which is a SHA-512 hash of
+// the ed25519 seed, so a key loaded from tor can't be converted back to
+// ed25519.PrivateKey.
+type OnionKey struct {
+	expanded [onionExpandedKeySize]byte
+	public   ed25519.PublicKey
+}
+
+// GenerateOnionKey generates a new OnionKey using entropy from rand.
+// If rand is nil, crypto/rand.Reader will be used.
+func GenerateOnionKey(rand io.Reader) (*OnionKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand)
+	if err != nil {
+		const format = "tornado: cannot generate onion key: %v"
+		return nil, fmt.Errorf(format, err)
+	}
+
+	return NewOnionKey(priv), nil
+}
+
+// NewOnionKey creates the OnionKey from the ed25519 private key.
+func NewOnionKey(priv ed25519.PrivateKey) *OnionKey {
+	key := &OnionKey{}
+
+	hash := sha512.Sum512(priv.Seed())
+	hash[0] &= 248
+	hash[31] &= 127
+	hash[31] |= 64
+
+	copy(key.expanded[:], hash[:])
+	key.public = onionPublicKey(key.expanded[:32])
+
+	return key
+}
+
+// PublicKey returns the public key of the onion service.
+func (k *OnionKey) PublicKey() ed25519.PublicKey {
+	return bytes.Clone(k.public)
+}
+
+// Address returns the onion address of the service, e.g. "xxx.onion".
+func (k *OnionKey) Address() string {
+	return OnionAddress(k.public)
+}
+
+// MarshalText encodes the key in the ED25519-V3:base64 format used by
+// the ADD_ONION command of the tor control protocol.
+func (k *OnionKey) MarshalText() ([]byte, error) {
+	text := onionKeyTextPrefix + base64.StdEncoding.EncodeToString(k.expanded[:])
+	return []byte(text), nil
+}
+
+// UnmarshalText decodes the key in the format produced by MarshalText.
+func (k *OnionKey) UnmarshalText(text []byte) error {
+	blob, ok := strings.CutPrefix(string(text), onionKeyTextPrefix)
+	if !ok {
+		return errors.New("tornado: onion key must have the ED25519-V3 prefix")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		const format = "tornado: cannot decode onion key: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	return k.setExpanded(raw)
+}
+
+// MarshalBinary encodes the key in the format of the hs_ed25519_secret_key
+// file, which tor keeps in the HiddenServiceDir.
+func (k *OnionKey) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, len(onionKeyFileHeader)+onionExpandedKeySize)
+	data = append(data, onionKeyFileHeader...)
+	data = append(data, k.expanded[:]...)
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes the key in the format of the hs_ed25519_secret_key
+// file.
+func (k *OnionKey) UnmarshalBinary(data []byte) error {
+	raw, ok := bytes.CutPrefix(data, []byte(onionKeyFileHeader))
+	if !ok {
+		return errors.New("tornado: onion key file has unexpected header")
+	}
+
+	return k.setExpanded(raw)
+}
+
+func (k *OnionKey) setExpanded(raw []byte) error {
+	if len(raw) != onionExpandedKeySize {
+		const format = "tornado: onion key must be %d bytes long, got %d"
+		return fmt.Errorf(format, onionExpandedKeySize, len(raw))
+	}
+
+	copy(k.expanded[:], raw)
+	k.public = onionPublicKey(k.expanded[:32])
+
+	return nil
+}
+
+// onionPublicKey derives the public key from the secret scalar, which is
+// the first half of the expanded key, using the constant time arithmetic.
+func onionPublicKey(scalar []byte) ed25519.PublicKey {
+	s, err := new(edwards25519.Scalar).SetBytesWithClamping(scalar)
+	if err != nil {
+		// The length of the scalar is always 32 bytes.
+		panic("tornado: invalid onion key scalar: " + err.Error())
+	}
+
+	return new(edwards25519.Point).ScalarBaseMult(s).Bytes()
+}
+
+// OnionAddress returns the v3 onion address for the public key of
+// the onion service, e.g. "xxx.onion".
+func OnionAddress(pub ed25519.PublicKey) string {
+	if len(pub) != ed25519.PublicKeySize {
+		panic("tornado: invalid onion service public key length")
+	}
+
+	data := make([]byte, 0, ed25519.PublicKeySize+3)
+	data = append(data, pub...)
+	data = append(data, onionAddressChecksum(pub)...)
+	data = append(data, onionAddressVersion)
+
+	return strings.ToLower(onionBase32.EncodeToString(data)) + ".onion"
+}
+
+// ParseOnionAddress validates the v3 onion address and returns the public
+// key of the onion service. The ".onion" suffix is optional.
+func ParseOnionAddress(addr string) (ed25519.PublicKey, error) {
+	id := strings.TrimSuffix(strings.ToLower(addr), ".onion")
+
+	data, err := onionBase32.DecodeString(strings.ToUpper(id))
+	if err != nil || len(data) != ed25519.PublicKeySize+3 {
+		const format = "tornado: malformed onion address %q"
+		return nil, fmt.Errorf(format, addr)
+	}
+
+	pub := ed25519.PublicKey(data[:ed25519.PublicKeySize])
+	checksum := data[ed25519.PublicKeySize : ed25519.PublicKeySize+2]
+
+	if data[len(data)-1] != onionAddressVersion {
+		const format = "tornado: unsupported onion address version %d in %q"
+		return nil, fmt.Errorf(format, data[len(data)-1], addr)
+	}
+
+	if !bytes.Equal(checksum, onionAddressChecksum(pub)) {
+		const format = "tornado: invalid onion address checksum in %q"
+		return nil, fmt.Errorf(format, addr)
+	}
+
+	return pub, nil
+}
+
+// onionAddressChecksum computes the checksum defined in the rend-spec-v3:
+// CHECKSUM = H(".onion checksum" | PUBKEY | VERSION)[:2].
+func onionAddressChecksum(pub ed25519.PublicKey) []byte {
+	data := make([]byte, 0, 48)
+	data = append(data, ".onion checksum"...)
+	data = append(data, pub...)
+	data = append(data, onionAddressVersion)
+
+	sum := sha3.Sum256(data)
+
+	return sum[:2]
+}
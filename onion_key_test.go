@@ -0,0 +1,154 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler     = (*OnionKey)(nil)
+	_ encoding.TextUnmarshaler   = (*OnionKey)(nil)
+	_ encoding.BinaryMarshaler   = (*OnionKey)(nil)
+	_ encoding.BinaryUnmarshaler = (*OnionKey)(nil)
+)
+
+func TestNewOnionKey(t *testing.T) {
+	t.Parallel()
+	t.Run("Public key should match the one derived by crypto/ed25519", func(t *testing.T) {
+		t.Parallel()
+
+		for i := byte(0); i < 8; i++ {
+			// arrange
+			pub, priv, err := ed25519.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{i}, 32)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			key := NewOnionKey(priv)
+
+			// assert
+			if !pub.Equal(key.PublicKey()) {
+				t.Fatalf("got public key %x, want %x", key.PublicKey(), pub)
+			}
+		}
+	})
+}
+
+func TestOnionKey_MarshalText(t *testing.T) {
+	t.Parallel()
+	t.Run("Should restore the same key after unmarshal", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		key, err := GenerateOnionKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		text, err := key.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got OnionKey
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		// assert
+		if !bytes.HasPrefix(text, []byte("ED25519-V3:")) {
+			t.Fatalf("unexpected key format %q", text)
+		}
+
+		if got.Address() != key.Address() {
+			t.Fatalf("got address %q, want %q", got.Address(), key.Address())
+		}
+	})
+}
+
+func TestOnionKey_MarshalBinary(t *testing.T) {
+	t.Parallel()
+	t.Run("Should restore the same key after unmarshal", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		key, err := GenerateOnionKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		data, err := key.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got OnionKey
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		// assert
+		if len(data) != 96 {
+			t.Fatalf("hs_ed25519_secret_key must be 96 bytes long, got %d", len(data))
+		}
+
+		if got.Address() != key.Address() {
+			t.Fatalf("got address %q, want %q", got.Address(), key.Address())
+		}
+	})
+
+	t.Run("Should reject data without the header", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var key OnionKey
+
+		// act
+		err := key.UnmarshalBinary(make([]byte, 96))
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
+
+func TestParseOnionAddress(t *testing.T) {
+	t.Parallel()
+	t.Run("Should accept a well-known onion address", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		const addr = "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion"
+
+		// act
+		pub, err := ParseOnionAddress(addr)
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if got := OnionAddress(pub); got != addr {
+			t.Fatalf("got address %q, want %q", got, addr)
+		}
+	})
+
+	t.Run("Should reject an address with invalid checksum", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		const addr = "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczbd.onion"
+
+		// act
+		_, err := ParseOnionAddress(addr)
+
+		// assert
+		if err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+var _ net.Listener = (*OnionService)(nil)
+
+func TestController_ListenOnion(t *testing.T) {
+	t.Parallel()
+	t.Run("Should publish onion service and forward connections to it", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		const serviceID = "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd"
+
+		commands := make(chan string, 2)
+		ctrl := newTestController(t, func(srv *textproto.Conn) {
+			for {
+				line, err := srv.ReadLine()
+				if err != nil {
+					return
+				}
+
+				commands <- line
+
+				if strings.HasPrefix(line, "ADD_ONION") {
+					_ = srv.PrintfLine("250-ServiceID=%s", serviceID)
+				}
+
+				_ = srv.PrintfLine("250 OK")
+			}
+		})
+
+		// act
+		srv, err := ctrl.ListenOnion(context.Background(), 80)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		// assert
+		if got := srv.Addr().String(); got != serviceID+".onion:80" {
+			t.Fatalf("unexpected onion address %q", got)
+		}
+
+		add := <-commands
+		target := add[strings.LastIndex(add, ",")+1:]
+
+		conn, err := net.Dial("tcp", target)
+		if err != nil {
+			t.Fatalf("cannot dial local target %q of %q: %v", target, add, err)
+		}
+		defer conn.Close()
+
+		accepted, err := srv.Accept()
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+		defer accepted.Close()
+
+		if err := srv.Close(); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if del := <-commands; del != "DEL_ONION "+serviceID {
+			t.Fatalf("unexpected command %q", del)
+		}
+	})
+}
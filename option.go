@@ -4,10 +4,43 @@
 
 package tornado
 
+import (
+	"log/slog"
+)
+
 type options struct {
-	numberOfProxy int
-	torrcOptions  []string
-	forwardDialer comboDialer
+	numberOfProxy   int
+	torrcOptions    []string
+	forwardDialer   comboDialer
+	onionClientAuth []onionClientAuth
+
+	bootstrapProgress func(BootstrapStatus)
+
+	logger   *slog.Logger
+	logLevel slog.Level
+
+	torBinary         string
+	minimumTorVersion *Version
+
+	dataDirectory string
+	unixSockets   bool
+	httpTunnel    bool
+	dnsPort       bool
+
+	restartPolicy *RestartPolicy
+	retryPolicy   *RetryPolicy
+	balancer      Balancer
+
+	config       Config
+	verifyConfig bool
+}
+
+func (s options) torBinaryPath() string {
+	if s.torBinary == "" {
+		return defaultTorBinary
+	}
+
+	return s.torBinary
 }
 
 // Option is an abstraction on the options.
@@ -7,6 +7,7 @@ package tornado // import "github.com/xorcare/tornado"
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"sync"
@@ -30,41 +31,110 @@ func NewPool(ctx context.Context, size int, ops ...Option) (*Pool, error) {
 		option.apply(&state)
 	}
 
+	if err := checkTorVersion(ctx, state); err != nil {
+		return nil, err
+	}
+
 	trc, err := newTorrcFromState(state)
 	if err != nil {
-		const format = "failed to create torrc: %v"
+		const format = "failed to create torrc: %w"
 		return nil, fmt.Errorf(format, err)
 	}
 
-	cmd, err := launchBackgroundTorDemon(ctx, trc)
+	if err := verifyTorrc(ctx, trc, state); err != nil {
+		_ = trc.cleanup()
+		return nil, err
+	}
+
+	cmd, err := launchBackgroundTorDemon(ctx, trc, state)
 	if err != nil {
+		_ = trc.cleanup()
+
 		const format = "failed to launch tor demon for the pool: %v"
+
 		return nil, fmt.Errorf(format, err)
 	}
 
-	closeFunc := makeCloseFunc(cmd)
-	pool := newFreePool(len(trc.socksPort), closeFunc)
+	tor := startDaemon(cmd, trc, state)
+
+	ctrl, err := dialController(ctx, trc.controlAddress, CookieAuth(trc.cookieAuthFile))
+	if err != nil {
+		_ = makeCloseFunc(tor, nil, trc)()
+
+		const format = "cannot open control connection for the pool: %v"
+
+		return nil, fmt.Errorf(format, err)
+	}
 
-	for _, port := range trc.socksPort {
-		prx, err := openSOCKS5Proxy(port, state.forwardDialer, nil)
+	tor.setController(ctrl)
+
+	closeFunc := makeCloseFunc(tor, ctrl, trc)
+	pool := newFreePool(len(trc.socksAddress), ctrl, closeFunc)
+	pool.tor = tor
+	pool.retryPolicy = state.retryPolicy
+	pool.balancer = state.balancer
+
+	for i, addr := range trc.socksAddress {
+		prx, err := openSOCKS5Proxy(addr, state.forwardDialer, ctrl, nil)
 		if err != nil {
+			_ = pool.Close()
+
 			const format = "cannot create proxy instance for pool: %v"
+
 			return nil, fmt.Errorf(format, err)
 		}
 
-		pool.Put(prx)
+		prx.tor = tor
+		prx.exitCountries = state.config.exitCountries()
+		prx.retryPolicy = state.retryPolicy
+
+		if len(trc.httpTunnelAddress) > 0 {
+			prx.httpTunnel = trc.httpTunnelAddress[i]
+		}
+
+		prx.dns = trc.dnsAddress
+
+		pool.add(prx)
 	}
 
 	return pool, nil
 }
 
+var (
+	// ErrPoolClosed is returned by the methods of the Pool after it
+	// has been closed.
+	ErrPoolClosed = errors.New("tornado: pool is closed")
+
+	// ErrPoolOverflow is returned by Pool.Put when the pool already
+	// contains all its proxy instances.
+	ErrPoolOverflow = errors.New("tornado: pool overflow")
+
+	// ErrForeignProxy is returned by Pool.Put when the proxy instance does
+	// not belong to the pool.
+	ErrForeignProxy = errors.New("tornado: proxy does not belong to the pool")
+)
+
 // A Pool is an abstraction for creating multiple proxy instances using
 // a single tor process to reduce resource usage.
 //
 // Also, Pool provides a minimal interface for managing a set of proxies
 // and their reuse.
 type Pool struct {
-	ch chan *Proxy
+	ch         chan *Proxy
+	controller *Controller
+	life       *lifetime
+	// tor is nil if the Pool is attached to the running tor demon.
+	tor *daemon
+	// members are the pools of the country pool, see NewCountryPool.
+	members []*Pool
+	// exit tracks the members of the country pool, which stopped working.
+	exit *memberExit
+	// retryPolicy is the default retry policy of the FloatingProxy.
+	retryPolicy *RetryPolicy
+	// balancer is the default balancer of the FloatingProxy.
+	balancer Balancer
+	// proxies are all proxies of the pool, including the taken ones.
+	proxies []*Proxy
 
 	closeFunc func() error
 	closeOnce sync.Once
@@ -73,30 +143,195 @@ type Pool struct {
 // Get gets a proxy instance from the pool.
 //
 // This operation can block the goroutine until a new proxy instance appears
-// in the pool.
-func (p *Pool) Get() *Proxy {
-	return <-p.ch
+// in the pool, use GetContext to limit the waiting time. If the pool is
+// closed, Get returns ErrPoolClosed.
+func (p *Pool) Get() (*Proxy, error) {
+	return p.GetContext(context.Background())
+}
+
+// GetContext gets a proxy instance from the pool, waiting until a new proxy
+// instance appears in the pool or the context is done. If the context is
+// done, GetContext returns the context's error. If the pool is closed,
+// GetContext returns ErrPoolClosed.
+func (p *Pool) GetContext(ctx context.Context) (*Proxy, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if p.life.ended() {
+		return nil, ErrPoolClosed
+	}
+
+	select {
+	case prx := <-p.ch:
+		return prx, nil
+	case <-p.life.done:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TryGet gets a proxy instance from the pool without blocking, ok is false
+// if there are no proxy instances in the pool or the pool is closed.
+func (p *Pool) TryGet() (prx *Proxy, ok bool) {
+	if p.life.ended() {
+		return nil, false
+	}
+
+	select {
+	case prx := <-p.ch:
+		return prx, true
+	default:
+		return nil, false
+	}
 }
 
 // Put puts the proxy instance back in the pool.
 //
-// Do not try to manually fill the Pool in excess of the size specified when
-// creating the pool, this can lead to blocking of the goroutine when pool
-// overflows.
-func (p *Pool) Put(prx *Proxy) {
+// Put returns ErrForeignProxy if the proxy instance was not obtained from
+// this pool, and ErrPoolOverflow if the pool already contains all its
+// proxy instances, e.g. when the same proxy instance is put twice.
+// If the pool is closed, Put does nothing.
+func (p *Pool) Put(prx *Proxy) error {
 	if !prx.isValid() {
 		panic("tornado: not possible to put an invalid proxy instance in the proxy pool")
 	}
 
+	if prx.life != p.life {
+		return ErrForeignProxy
+	}
+
+	if p.life.ended() {
+		return nil
+	}
+
+	select {
+	case p.ch <- prx:
+		return nil
+	default:
+		return ErrPoolOverflow
+	}
+}
+
+// add adds the new proxy instance to the pool, the pool becomes its owner.
+func (p *Pool) add(prx *Proxy) {
+	prx.life = p.life
+	p.proxies = append(p.proxies, prx)
 	p.ch <- prx
 }
 
+// Shutdown gracefully stops the tor demon running in the background.
+//
+// Shutdown works by first closing the pool, so Get and GetContext return
+// ErrPoolClosed and the proxies obtained from the pool return ErrClosed
+// from DialContext, then waiting for the active connections of all
+// proxies of the pool to be closed, and then stopping the tor demon.
+// If the provided context expires before the connections are closed,
+// Shutdown stops the tor demon, aborting the remaining connections,
+// and returns the context's error.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	p.life.end()
+
+	err := p.life.conns.wait(ctx)
+	if closeErr := p.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// Done returns a channel that is closed when the Pool stops working:
+// the Pool is closed, or the tor demon serving it exited and can't be
+// restarted, see WithRestartPolicy.
+//
+// If the Pool is attached to the running tor demon, the channel is closed
+// only when the Pool is closed. If the Pool is created by NewCountryPool,
+// the channel is closed when any of its tor demons stops working.
+func (p *Pool) Done() <-chan struct{} {
+	if p.exit != nil {
+		return p.exit.done
+	}
+
+	if p.tor == nil {
+		return p.life.done
+	}
+
+	return p.tor.done
+}
+
+// Err returns nil if Done is not yet closed. If Done is closed, Err
+// returns the error explaining why the tor demon exited, or ErrPoolClosed
+// if the Pool was closed.
+func (p *Pool) Err() error {
+	select {
+	case <-p.Done():
+	default:
+		return nil
+	}
+
+	if p.exit != nil {
+		if err := p.exit.err(); err != nil {
+			return err
+		}
+	}
+
+	if p.tor != nil {
+		if err := p.tor.exitError(); err != nil {
+			return err
+		}
+	}
+
+	return ErrPoolClosed
+}
+
+// Controller returns the client of the control port of the tor demon
+// serving the pool.
+//
+// The Controller is owned by the Pool, it must not be closed manually.
+func (p *Pool) Controller() *Controller {
+	return p.controller
+}
+
+// NewIdentity switches the tor demon serving the pool to clean circuits,
+// so new connections of all proxies of the pool don't share any circuits
+// with old ones.
+func (p *Pool) NewIdentity(ctx context.Context) error {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if len(p.members) > 0 {
+		for _, member := range p.members {
+			if err := member.NewIdentity(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if p.controller == nil {
+		return ErrNoController
+	}
+
+	return p.controller.NewIdentity(ctx)
+}
+
 // Close stops the tor demon running in the background.
 //
 // This operation will not wait for active connections to close,
-// they will be aborted.
+// they will be aborted. After Close, Get and GetContext return
+// ErrPoolClosed, and the proxies obtained from the pool return ErrClosed
+// from DialContext. Use Shutdown to wait for active connections to close.
 func (p *Pool) Close() (err error) {
 	p.closeOnce.Do(func() {
+		p.life.end()
+
 		if p.closeFunc != nil {
 			err = p.closeFunc()
 		}
@@ -108,10 +343,12 @@ func (p *Pool) Close() (err error) {
 	return err
 }
 
-func newFreePool(number int, closeFunc func() error) *Pool {
+func newFreePool(number int, ctrl *Controller, closeFunc func() error) *Pool {
 	pool := &Pool{
-		ch:        make(chan *Proxy, number),
-		closeFunc: closeFunc,
+		ch:         make(chan *Proxy, number),
+		controller: ctrl,
+		life:       newLifetime(),
+		closeFunc:  closeFunc,
 	}
 	runtime.SetFinalizer(pool, (*Pool).Close)
 
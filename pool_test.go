@@ -6,8 +6,10 @@ package tornado
 
 import (
 	"context"
+	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/xorcare/tornado/internal/torproject"
 )
@@ -28,8 +30,13 @@ func TestNewPool(t *testing.T) {
 
 		defer pool.Close()
 
+		prx, err := pool.Get()
+		if err != nil {
+			t.Fatalf("cannot get proxy from pool: %v", err)
+		}
+
 		// act
-		cr, err := torproject.CheckContextDialer(pool.Get())
+		cr, err := torproject.CheckContextDialer(prx)
 		if err != nil {
 			t.Fatalf("failed make torproject check: %v", err)
 		}
@@ -97,4 +104,190 @@ func TestPool_Close(t *testing.T) {
 			t.Fatal("should not get an error:", err)
 		}
 	})
+
+	t.Run("Should unblock pending GetContext with ErrPoolClosed", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		_, _ = pool.Get()
+
+		result := make(chan error, 1)
+
+		go func() {
+			_, err := pool.GetContext(context.Background())
+			result <- err
+		}()
+
+		// act
+		_ = pool.Close()
+
+		// assert
+		if err := <-result; !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("ErrPoolClosed error was expected, but got another one: %v", err)
+		}
+	})
+
+	t.Run("Should return ErrPoolClosed from Get after close", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		_ = pool.Close()
+
+		// act
+		_, err := pool.Get()
+
+		// assert
+		if !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("ErrPoolClosed error was expected, but got another one: %v", err)
+		}
+	})
+
+	t.Run("Should ignore Put after close", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		prx, _ := pool.Get()
+		_ = pool.Close()
+
+		// act
+		err := pool.Put(prx)
+
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if _, ok := pool.TryGet(); ok {
+			t.Fatal("proxy should not be returned to the closed pool")
+		}
+	})
+
+	t.Run("Should make proxies from the pool return ErrClosed", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		prx, _ := pool.Get()
+		_ = pool.Close()
+
+		// act
+		_, err := prx.DialContext(context.Background(), "tcp", "127.0.0.1:80")
+
+		// assert
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("ErrClosed error was expected, but got another one: %v", err)
+		}
+	})
+}
+
+// newTestPool creates a pool of proxies that are not backed by tor.
+func newTestPool(t *testing.T, size int) *Pool {
+	t.Helper()
+
+	pool := newFreePool(size, nil, nil)
+
+	for i := 0; i < size; i++ {
+		prx, err := openSOCKS5Proxy(tcpEndpoint(9050+i), nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pool.add(prx)
+	}
+
+	return pool
+}
+
+func TestPool_GetContext(t *testing.T) {
+	t.Parallel()
+	t.Run("Should return proxy from the pool", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+
+		// act
+		prx, err := pool.GetContext(context.Background())
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if prx == nil {
+			t.Fatal("proxy was expected")
+		}
+	})
+
+	t.Run("Should return context error when the pool is empty", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		_, _ = pool.Get()
+
+		ctx, done := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		t.Cleanup(done)
+
+		// act
+		_, err := pool.GetContext(ctx)
+
+		// assert
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("context.DeadlineExceeded error was expected, but got another one: %v", err)
+		}
+	})
+}
+
+func TestPool_TryGet(t *testing.T) {
+	t.Parallel()
+	t.Run("Should not block when the pool is empty", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+
+		// act
+		_, first := pool.TryGet()
+		_, second := pool.TryGet()
+
+		// assert
+		if !first || second {
+			t.Fatalf("got %v and %v, want true and false", first, second)
+		}
+	})
+}
+
+func TestPool_Put(t *testing.T) {
+	t.Parallel()
+	t.Run("Should return error on overflow", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		prx, _ := pool.Get()
+
+		if err := pool.Put(prx); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		// act
+		err := pool.Put(prx)
+
+		// assert
+		if !errors.Is(err, ErrPoolOverflow) {
+			t.Fatalf("ErrPoolOverflow error was expected, but got another one: %v", err)
+		}
+	})
+
+	t.Run("Should return error for proxy from another pool", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		other := newTestPool(t, 1)
+		_, _ = pool.Get()
+		prx, _ := other.Get()
+
+		// act
+		err := pool.Put(prx)
+
+		// assert
+		if !errors.Is(err, ErrForeignProxy) {
+			t.Fatalf("ErrForeignProxy error was expected, but got another one: %v", err)
+		}
+	})
 }
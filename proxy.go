@@ -6,17 +6,19 @@ package tornado
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
-	"net/url"
-	"os"
-	"os/exec"
 	"runtime"
+	"strconv"
 	"sync"
-
-	"golang.org/x/net/proxy"
+	"sync/atomic"
 )
 
+// ErrClosed is returned by the methods of the Proxy after the Proxy or
+// the Pool from which it was obtained has been closed.
+var ErrClosed = errors.New("tornado: use of closed proxy")
+
 // NewProxy creates new instance of Proxy.
 //
 // If the Proxy was created using NewProxy, it must be closed wia using Close
@@ -34,32 +36,82 @@ func NewProxy(ctx context.Context, ops ...Option) (*Proxy, error) {
 		option.apply(&state)
 	}
 
+	if err := checkTorVersion(ctx, state); err != nil {
+		return nil, err
+	}
+
 	trc, err := newTorrcFromState(state)
 	if err != nil {
 		return nil, err
 	}
 
-	cmd, err := launchBackgroundTorDemon(ctx, trc)
+	if err := verifyTorrc(ctx, trc, state); err != nil {
+		_ = trc.cleanup()
+		return nil, err
+	}
+
+	cmd, err := launchBackgroundTorDemon(ctx, trc, state)
 	if err != nil {
+		_ = trc.cleanup()
+
 		const format = "cannot run tor demon for a single proxy: %v"
+
 		return nil, fmt.Errorf(format, err)
 	}
 
-	closeFunc := makeCloseFunc(cmd)
+	tor := startDaemon(cmd, trc, state)
 
-	prx, err := openSOCKS5Proxy(trc.socksPort[0], state.forwardDialer, closeFunc)
+	ctrl, err := dialController(ctx, trc.controlAddress, CookieAuth(trc.cookieAuthFile))
 	if err != nil {
+		_ = makeCloseFunc(tor, nil, trc)()
+
+		const format = "cannot open control connection for a single proxy: %v"
+
+		return nil, fmt.Errorf(format, err)
+	}
+
+	tor.setController(ctrl)
+
+	closeFunc := makeCloseFunc(tor, ctrl, trc)
+
+	prx, err := openSOCKS5Proxy(trc.socksAddress[0], state.forwardDialer, ctrl, closeFunc)
+	if err != nil {
+		_ = closeFunc()
+
 		const format = "cannot create proxy instance: %v"
+
 		return nil, fmt.Errorf(format, err)
 	}
 
+	prx.tor = tor
+	prx.exitCountries = state.config.exitCountries()
+	prx.retryPolicy = state.retryPolicy
+
+	if len(trc.httpTunnelAddress) > 0 {
+		prx.httpTunnel = trc.httpTunnelAddress[0]
+	}
+
+	prx.dns = trc.dnsAddress
+
 	return prx, nil
 }
 
 // Proxy returns a ContextDialer that makes connections to the given
 // address over tor network.
 type Proxy struct {
-	proxy ContextDialer
+	socks      endpoint
+	httpTunnel endpoint
+	dns        endpoint
+	forward    dialer
+	controller *Controller
+	life       *lifetime
+	// tor is nil if the Proxy is attached to the running tor demon.
+	tor           *daemon
+	exitCountries []string
+	retryPolicy   *RetryPolicy
+	// active is the number of the active connections of the proxy,
+	// including the connections being dialed.
+	active atomic.Int64
 
 	valid     bool
 	closeFunc func() error
@@ -74,6 +126,16 @@ type Proxy struct {
 // connected, any expiration of the context will not affect the
 // connection.
 //
+// If the context carries the isolation key set by WithIsolationKey,
+// the connection is made over circuits dedicated to the key.
+//
+// If the retry policy is set by WithRetryPolicy, the dial refused by tor
+// is retried according to it.
+//
+// The failures of the dial are reported as *DialError, the reason of
+// the refusal of tor can be checked using errors.Is with the SOCKSReply
+// constants, e.g. ErrHostUnreachable.
+//
 // See func Dial of the net package of standard library for a
 // description of the network and address parameters.
 func (p *Proxy) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
@@ -81,7 +143,77 @@ func (p *Proxy) DialContext(ctx context.Context, network, address string) (net.C
 		panic("tornado: nil context")
 	}
 
-	return p.proxy.DialContext(ctx, network, address)
+	dial := func() (*Proxy, net.Conn, error) {
+		conn, err := p.dialTracked(ctx, network, address)
+		return p, conn, err
+	}
+
+	return retryDial(ctx, p.retryPolicy, dial)
+}
+
+// dialTracked makes one attempt to dial, the connection is tracked by
+// the lifetime of the proxy.
+func (p *Proxy) dialTracked(ctx context.Context, network, address string) (net.Conn, error) {
+	// The connection is registered before the check, so Shutdown can't
+	// miss the connection being dialed.
+	p.life.conns.add()
+
+	if p.life.ended() {
+		p.life.conns.done()
+		return nil, ErrClosed
+	}
+
+	p.active.Add(1)
+
+	conn, err := p.dial(ctx, network, address)
+	if err != nil {
+		p.active.Add(-1)
+		p.life.conns.done()
+
+		return nil, err
+	}
+
+	return &trackedConn{Conn: conn, conns: &p.life.conns, active: &p.active}, nil
+}
+
+func (p *Proxy) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	fail := func(err error) (net.Conn, error) {
+		dialErr := &DialError{Network: network, Address: address, Err: err}
+		errors.As(err, &dialErr.Reply)
+
+		return nil, dialErr
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		const format = "network %q is not supported"
+		return fail(fmt.Errorf(format, network))
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return fail(err)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		const format = "invalid port %q"
+		return fail(fmt.Errorf(format, portStr))
+	}
+
+	conn, err := p.dialSOCKS(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	_, err = socks5Exchange(ctx, conn, isolationAuth(ctx), socks5CommandConnect, host, int(port))
+	if err != nil {
+		_ = conn.Close()
+		return fail(err)
+	}
+
+	return conn, nil
 }
 
 // Dial connects to the address on the named network over tor network.
@@ -92,7 +224,7 @@ func (p *Proxy) DialContext(ctx context.Context, network, address string) (net.C
 // Dial uses context.Background internally; to specify the context, use
 // DialContext.
 func (p *Proxy) Dial(network, address string) (c net.Conn, err error) {
-	return p.proxy.DialContext(context.Background(), network, address)
+	return p.DialContext(context.Background(), network, address)
 }
 
 // Close stops the tor demon running in the background.
@@ -100,10 +232,12 @@ func (p *Proxy) Dial(network, address string) (c net.Conn, err error) {
 // Close has no effect.
 //
 // This operation will not wait for active connections to close,
-// they will be aborted.
+// they will be aborted. After Close, DialContext returns ErrClosed.
+// Use Shutdown to wait for active connections to close.
 func (p *Proxy) Close() (err error) {
 	p.closeOnce.Do(func() {
 		if p.closeFunc != nil {
+			p.life.end()
 			err = p.closeFunc()
 		}
 
@@ -114,49 +248,132 @@ func (p *Proxy) Close() (err error) {
 	return err
 }
 
-func (p *Proxy) isValid() bool {
-	return p != nil && p.valid
+// Shutdown gracefully stops the tor demon running in the background.
+// If the Proxy was created using NewPool directly instead of NewProxy,
+// Shutdown has no effect.
+//
+// Shutdown works by first making DialContext return ErrClosed, then
+// waiting for the active connections to be closed, and then stopping
+// the tor demon. If the provided context expires before the connections
+// are closed, Shutdown stops the tor demon, aborting the remaining
+// connections, and returns the context's error.
+func (p *Proxy) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if p.closeFunc == nil {
+		return nil
+	}
+
+	p.life.end()
+
+	err := p.life.conns.wait(ctx)
+	if closeErr := p.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
 }
 
-func openSOCKS5Proxy(port int, forward dialer, closeFunc func() error) (*Proxy, error) {
-	const socksFormat = "socks5://localhost:%d"
+// Done returns a channel that is closed when the Proxy stops working:
+// the Proxy is closed, or the tor demon serving it exited and can't be
+// restarted, see WithRestartPolicy.
+//
+// If the Proxy is attached to the running tor demon, the channel is closed
+// only when the Proxy is closed.
+func (p *Proxy) Done() <-chan struct{} {
+	if p.tor == nil {
+		return p.life.done
+	}
 
-	socks5URL, err := url.Parse(fmt.Sprintf(socksFormat, port))
-	if err != nil {
-		const format = "cannot create socks5 url: %v"
-		return nil, fmt.Errorf(format, err)
+	return p.tor.done
+}
+
+// Err returns nil if Done is not yet closed. If Done is closed, Err
+// returns the error explaining why the tor demon exited, or ErrClosed if
+// the Proxy was closed.
+func (p *Proxy) Err() error {
+	select {
+	case <-p.Done():
+	default:
+		return nil
 	}
 
-	dialer, err := proxy.FromURL(socks5URL, forward)
-	if err != nil {
-		const format = "cannot create proxy dialer: %v"
-		return nil, fmt.Errorf(format, err)
+	if p.tor != nil {
+		if err := p.tor.exitError(); err != nil {
+			return err
+		}
+	}
+
+	return ErrClosed
+}
+
+// Controller returns the client of the control port of the tor demon
+// serving the proxy.
+//
+// The Controller is owned by the Proxy or the Pool from which the Proxy
+// was obtained, it must not be closed manually.
+func (p *Proxy) Controller() *Controller {
+	return p.controller
+}
+
+// NewIdentity switches the tor demon serving the proxy to clean circuits,
+// so new connections don't share any circuits with old ones.
+//
+// If the Proxy was obtained from the Pool, the new identity applies to
+// all proxies of the pool.
+func (p *Proxy) NewIdentity(ctx context.Context) error {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if p.controller == nil {
+		return ErrNoController
+	}
+
+	return p.controller.NewIdentity(ctx)
+}
+
+func (p *Proxy) isValid() bool {
+	return p != nil && p.valid
+}
+
+func openSOCKS5Proxy(addr endpoint, forward dialer, ctrl *Controller, closeFunc func() error) (*Proxy, error) {
+	if addr.network != "tcp" && addr.network != "unix" {
+		const format = "unsupported network %q of socks port"
+		return nil, fmt.Errorf(format, addr.network)
 	}
 
 	prx := &Proxy{
-		proxy:     dialer.(ContextDialer),
-		valid:     true,
-		closeFunc: closeFunc,
+		socks:      addr,
+		forward:    forward,
+		controller: ctrl,
+		life:       newLifetime(),
+		valid:      true,
+		closeFunc:  closeFunc,
 	}
 	runtime.SetFinalizer(prx, (*Proxy).Close)
 
 	return prx, nil
 }
 
-func makeCloseFunc(cmd *exec.Cmd) func() error {
-	return func() error {
-		if err := cmd.Process.Signal(os.Interrupt); err != nil {
-			format := "an error occurred while sending, a signal to interrupt" +
-				" the operation of the tor demon: %v"
-			return fmt.Errorf(format, err)
-		}
+func makeCloseFunc(tor *daemon, ctrl *Controller, trc torrc) func() error {
+	return func() (err error) {
+		defer func() {
+			// The data directory is removed even if the tor demon was
+			// not stopped gracefully.
+			if rmErr := trc.cleanup(); err == nil {
+				err = rmErr
+			}
+		}()
 
-		if err := cmd.Wait(); err != nil {
-			const format = "error while waiting is the result of sending," +
-				" a signal to interrupt the command: %v"
-			return fmt.Errorf(format, err)
+		if ctrl != nil {
+			// The error is not important, tor closes the control
+			// connection on exit anyway.
+			_ = ctrl.Close()
 		}
 
-		return nil
+		return tor.shutdown()
 	}
 }
@@ -153,3 +153,89 @@ func TestProxy_Close(t *testing.T) {
 		}
 	})
 }
+
+func TestProxy_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	newProxy := func(t *testing.T) (prx *Proxy, closed <-chan struct{}) {
+		t.Helper()
+
+		addr, _ := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+		ch := make(chan struct{})
+
+		prx, err := openSOCKS5Proxy(addr, nil, nil, func() error {
+			close(ch)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return prx, ch
+	}
+
+	t.Run("Should wait for active connections to close", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, closed := newProxy(t)
+
+		conn, err := prx.DialContext(context.Background(), "tcp", "example.com:80")
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		result := make(chan error, 1)
+
+		// act
+		go func() {
+			result <- prx.Shutdown(context.Background())
+		}()
+
+		// assert
+		select {
+		case <-closed:
+			t.Fatal("tor demon should not be stopped while the connection is active")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		if _, err := prx.DialContext(context.Background(), "tcp", "example.com:80"); !errors.Is(err, ErrClosed) {
+			t.Fatalf("ErrClosed error was expected, but got another one: %v", err)
+		}
+
+		_ = conn.Close()
+
+		if err := <-result; err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		<-closed
+	})
+
+	t.Run("Should stop tor demon when the context expires", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, closed := newProxy(t)
+
+		conn, err := prx.DialContext(context.Background(), "tcp", "example.com:80")
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+
+		ctx, done := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		t.Cleanup(done)
+
+		// act
+		err = prx.Shutdown(ctx)
+
+		// assert
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("context.DeadlineExceeded error was expected, but got another one: %v", err)
+		}
+
+		<-closed
+	})
+}
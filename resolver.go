@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/xorcare/tornado/internal/freeport"
+)
+
+// A Resolver looks up names and numbers over tor network using the RESOLVE
+// and RESOLVE_PTR extensions of the SOCKS5 protocol, so the DNS queries do
+// not leak outside of tor network. The methods are compatible in shape
+// with the methods of net.Resolver.
+//
+// The isolation key set by WithIsolationKey is respected, so the lookups
+// with different isolation keys never share circuits.
+type Resolver struct {
+	prx *Proxy
+}
+
+// Resolver returns a new Resolver, which looks up names and numbers over
+// tor network through the proxy.
+func (p *Proxy) Resolver() *Resolver {
+	return &Resolver{prx: p}
+}
+
+// LookupHost looks up the given host over tor network. It returns a slice
+// of the addresses of the host, tor resolves only one address per lookup,
+// so the slice has one element. IP addresses are returned as is.
+//
+// The error is *net.DNSError, unless the context is done or the proxy is
+// closed, then it is the error of the context or ErrClosed.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if _, err := netip.ParseAddr(host); err == nil {
+		return []string{host}, nil
+	}
+
+	addr, err := r.lookup(ctx, socks5CommandResolve, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if !addr.ip.IsValid() {
+		return nil, r.dnsError(host, "unexpected reply without address", false)
+	}
+
+	return []string{addr.ip.String()}, nil
+}
+
+// LookupAddr performs a reverse lookup for the given address over tor
+// network, returning a list of names mapping to that address, tor resolves
+// only one name per lookup, so the list has one element.
+//
+// The error is *net.DNSError, unless the context is done or the proxy is
+// closed, then it is the error of the context or ErrClosed.
+func (r *Resolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return nil, r.dnsError(addr, "unrecognized address", false)
+	}
+
+	reply, err := r.lookup(ctx, socks5CommandResolvePTR, ip.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.name == "" {
+		return nil, r.dnsError(addr, "unexpected reply without name", false)
+	}
+
+	// Names returned by net.Resolver are fully qualified.
+	return []string{strings.TrimSuffix(reply.name, ".") + "."}, nil
+}
+
+func (r *Resolver) lookup(ctx context.Context, command byte, name string) (socks5Addr, error) {
+	p := r.prx
+
+	p.life.conns.add()
+	defer p.life.conns.done()
+
+	if p.life.ended() {
+		return socks5Addr{}, ErrClosed
+	}
+
+	conn, err := p.dialSOCKS(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return socks5Addr{}, ctx.Err()
+		}
+
+		return socks5Addr{}, r.dnsError(name, err.Error(), false)
+	}
+
+	defer conn.Close()
+
+	addr, err := socks5Exchange(ctx, conn, isolationAuth(ctx), command, name, 0)
+	if err != nil {
+		if ctx.Err() != nil {
+			return socks5Addr{}, ctx.Err()
+		}
+
+		return socks5Addr{}, r.dnsError(name, err.Error(), isSOCKS5NotFound(err))
+	}
+
+	return addr, nil
+}
+
+func (r *Resolver) dnsError(name, message string, notFound bool) *net.DNSError {
+	return &net.DNSError{
+		Err:        message,
+		Name:       name,
+		Server:     r.prx.socks.address,
+		IsNotFound: notFound,
+	}
+}
+
+// isSOCKS5NotFound reports whether the error is the reply of tor to
+// the failed lookup, tor replies "host unreachable" when the name can't be
+// resolved.
+func isSOCKS5NotFound(err error) bool {
+	return errors.Is(err, ErrHostUnreachable)
+}
+
+// WithDNSPort makes the tor demon listen on the DNSPort, so external
+// processes can resolve names over tor network by sending DNS queries over
+// UDP to it, see Proxy.DNSAddr. One DNSPort is shared by all proxies of
+// the tor demon.
+//
+// The DNS port always listens on the loopback address, even if
+// WithUnixSockets is used. Keep in mind that any local process can use it.
+func WithDNSPort() Option {
+	fun := func(s *options) {
+		s.dnsPort = true
+	}
+
+	return optionFunc(fun)
+}
+
+// dnsEndpoint returns the address of the DNS port of the tor demon on a free
+// UDP port.
+func dnsEndpoint() (endpoint, error) {
+	port, err := freeport.UDP()
+	if err != nil {
+		const format = "cannot get free port for dns: %v"
+		return endpoint{}, fmt.Errorf(format, err)
+	}
+
+	return endpoint{network: "udp", address: tcpEndpoint(port).address}, nil
+}
+
+// DNSAddr returns the "host:port" UDP address of the DNS port of the tor
+// demon serving the proxy, it is empty if the DNS port is not enabled, see
+// WithDNSPort.
+func (p *Proxy) DNSAddr() string {
+	return p.dns.address
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolver_LookupHost(t *testing.T) {
+	t.Parallel()
+
+	newTestProxy := func(t *testing.T, reply byte) (*Proxy, <-chan socks5Request) {
+		t.Helper()
+
+		addr, requests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", reply)
+
+		prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return prx, requests
+	}
+
+	t.Run("Should resolve host with resolve command", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, requests := newTestProxy(t, 0x00)
+
+		// act
+		addrs, err := prx.Resolver().LookupHost(context.Background(), "example.com")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if want := []string{"127.0.0.1"}; !reflect.DeepEqual(addrs, want) {
+			t.Fatalf("got addresses %v, want %v", addrs, want)
+		}
+
+		if req := <-requests; req.command != 0xF0 || req.host != "example.com" {
+			t.Fatalf("unexpected request %+v", req)
+		}
+	})
+
+	t.Run("Should return IP address as is", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx := &Proxy{}
+
+		// act
+		addrs, err := prx.Resolver().LookupHost(context.Background(), "192.0.2.1")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if want := []string{"192.0.2.1"}; !reflect.DeepEqual(addrs, want) {
+			t.Fatalf("got addresses %v, want %v", addrs, want)
+		}
+	})
+
+	t.Run("Should use credentials of isolation key", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, requests := newTestProxy(t, 0x00)
+		ctx := WithIsolationKey(context.Background(), "session")
+
+		// act
+		_, err := prx.Resolver().LookupHost(ctx, "example.com")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if req := <-requests; req.username == "" || req.password != "session" {
+			t.Fatalf("unexpected request %+v", req)
+		}
+	})
+
+	t.Run("Should return not found DNS error on host unreachable", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, _ := newTestProxy(t, 0x04)
+
+		// act
+		_, err := prx.Resolver().LookupHost(context.Background(), "example.invalid")
+
+		// assert
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) {
+			t.Fatalf("got error %v, want *net.DNSError", err)
+		}
+
+		if !dnsErr.IsNotFound || dnsErr.Name != "example.invalid" {
+			t.Fatalf("unexpected DNS error %+v", dnsErr)
+		}
+	})
+
+	t.Run("Should return context error when context is canceled", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, _ := newTestProxy(t, 0x00)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		_, err := prx.Resolver().LookupHost(ctx, "example.com")
+
+		// assert
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got error %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("Should return ErrClosed after close", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, _ := newTestProxy(t, 0x00)
+		prx.closeFunc = func() error { return nil }
+		_ = prx.Close()
+
+		// act
+		_, err := prx.Resolver().LookupHost(context.Background(), "example.com")
+
+		// assert
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("got error %v, want %v", err, ErrClosed)
+		}
+	})
+}
+
+func TestResolver_LookupAddr(t *testing.T) {
+	t.Parallel()
+	t.Run("Should resolve address with resolve ptr command", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		addr, requests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+
+		prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		names, err := prx.Resolver().LookupAddr(context.Background(), "2001:db8::1")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if want := []string{testSOCKS5PTRName + "."}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("got names %v, want %v", names, want)
+		}
+
+		if req := <-requests; req.command != 0xF1 || req.host != "2001:db8::1" {
+			t.Fatalf("unexpected request %+v", req)
+		}
+	})
+
+	t.Run("Should return DNS error on invalid address", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx := &Proxy{}
+
+		// act
+		_, err := prx.Resolver().LookupAddr(context.Background(), "example.com")
+
+		// assert
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) {
+			t.Fatalf("got error %v, want *net.DNSError", err)
+		}
+	})
+}
+
+func TestWithDNSPort(t *testing.T) {
+	t.Parallel()
+	t.Run("Should add single dns port", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		state := options{numberOfProxy: 2}
+		WithDNSPort().apply(&state)
+
+		// act
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = trc.cleanup()
+		})
+
+		// assert
+		if trc.dnsAddress.address == "" {
+			t.Fatal("dns address should not be empty")
+		}
+
+		if got := strings.Count(trc.torrc, "DNSPort "+trc.dnsAddress.address+"\n"); got != 1 {
+			t.Fatalf("torrc contains %d dns ports, want 1:\n%s", got, trc.torrc)
+		}
+
+		conn, err := net.ListenPacket("udp", trc.dnsAddress.address)
+		if err != nil {
+			t.Fatal("dns port should be free for udp:", err)
+		}
+
+		_ = conn.Close()
+	})
+
+	t.Run("Should not add dns port by default", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		state := options{numberOfProxy: 1}
+
+		// act
+		trc, err := newTorrcFromState(state)
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		t.Cleanup(func() {
+			_ = trc.cleanup()
+		})
+
+		// assert
+		if strings.Contains(trc.torrc, "DNSPort") {
+			t.Fatalf("torrc should not contain dns port:\n%s", trc.torrc)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryMinBackoff  = 250 * time.Millisecond
+	defaultRetryMaxBackoff  = 5 * time.Second
+)
+
+// defaultRetryableReplies are the reply codes of tor, which usually mean
+// the failure of the circuit or the exit node, rather than the destination.
+var defaultRetryableReplies = []SOCKSReply{
+	ErrGeneralFailure,
+	ErrNetworkUnreachable,
+	ErrHostUnreachable,
+	ErrTTLExpired,
+	ErrOnionIntroFailed,
+	ErrOnionRendezvousFailed,
+	ErrOnionIntroTimeout,
+}
+
+// A RetryPolicy configures retrying of the dial, which failed because tor
+// refused the stream, e.g. the exit node could not reach the destination.
+// Other failures, such as the done context or the closed proxy, are not
+// retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts to dial, including
+	// the first one. If zero, three attempts are made.
+	MaxAttempts int
+	// MinBackoff is the delay before the second attempt, it doubles after
+	// each unsuccessful attempt. If zero, 250 milliseconds is used.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between attempts. If zero, five
+	// seconds is used.
+	MaxBackoff time.Duration
+	// Retryable is the list of the reply codes of tor, which are retried.
+	// If empty, the codes meaning the failure of the circuit are used:
+	// ErrGeneralFailure, ErrNetworkUnreachable, ErrHostUnreachable,
+	// ErrTTLExpired, ErrOnionIntroFailed, ErrOnionRendezvousFailed and
+	// ErrOnionIntroTimeout.
+	Retryable []SOCKSReply
+	// NewCircuit makes the proxy request new circuits using the NEWNYM
+	// signal before each retry, if the control connection is available,
+	// see Proxy.NewIdentity. By default, the circuits are kept.
+	//
+	// The signal switches all proxies of the tor demon to new circuits,
+	// so the failures of a single destination make the unrelated streams
+	// of the other proxies of the Pool use new circuits too. Tor rate
+	// limits the signal, so frequent retries don't get new circuits each
+	// time.
+	NewCircuit bool
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultRetryMaxAttempts
+	}
+
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) minBackoff() time.Duration {
+	if p.MinBackoff <= 0 {
+		return defaultRetryMinBackoff
+	}
+
+	return p.MinBackoff
+}
+
+func (p RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return defaultRetryMaxBackoff
+	}
+
+	return max(p.MaxBackoff, p.minBackoff())
+}
+
+// retryable reports whether the error is the reply of tor, which is
+// retried according to the policy.
+func (p RetryPolicy) retryable(err error) bool {
+	var dialErr *DialError
+	if !errors.As(err, &dialErr) || dialErr.Reply == 0 {
+		return false
+	}
+
+	if len(p.Retryable) == 0 {
+		return slices.Contains(defaultRetryableReplies, dialErr.Reply)
+	}
+
+	return slices.Contains(p.Retryable, dialErr.Reply)
+}
+
+// WithRetryPolicy enables retrying of the dial of the Proxy and
+// the FloatingProxy, which failed because tor refused the stream. Retries
+// of the Proxy use the same SocksPort, retries of the FloatingProxy move
+// to the next proxy of the pool.
+//
+// When all attempts fail, the error of the last attempt is returned. When
+// the context is done while waiting for the next attempt, the context's
+// error joined with the error of the last attempt is returned.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	fun := func(s *options) {
+		s.retryPolicy = &policy
+	}
+
+	return optionFunc(fun)
+}
+
+// retryDial calls dial until it succeeds, or fails with the error, which is
+// not retryable according to the policy, or the attempts are exhausted.
+// The dial function returns the proxy used for the attempt, it is used to
+// request new circuits. If the policy is nil, dial is called once.
+func retryDial(ctx context.Context, policy *RetryPolicy, dial func() (*Proxy, net.Conn, error)) (net.Conn, error) {
+	prx, conn, err := dial()
+	if policy == nil {
+		return conn, err
+	}
+
+	backoff := policy.minBackoff()
+
+	for attempt := 1; err != nil && attempt < policy.maxAttempts(); attempt++ {
+		if !policy.retryable(err) {
+			return nil, err
+		}
+
+		if policy.NewCircuit && prx != nil && prx.controller != nil {
+			// The error is not important, the attempt is made anyway
+			// with the circuits available.
+			_ = prx.NewIdentity(ctx)
+		}
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		prx, conn, err = dial()
+		backoff = min(backoff*2, policy.maxBackoff())
+	}
+
+	return conn, err
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+func TestWithRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	newTestProxy := func(t *testing.T, reply byte, policy RetryPolicy) (*Proxy, <-chan socks5Request) {
+		t.Helper()
+
+		addr, requests := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", reply)
+
+		prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var state options
+		WithRetryPolicy(policy).apply(&state)
+		prx.retryPolicy = state.retryPolicy
+
+		return prx, requests
+	}
+
+	tests := []struct {
+		name     string
+		reply    byte
+		policy   RetryPolicy
+		attempts int
+	}{
+		{
+			name:     "Should retry retryable reply until attempts are exhausted",
+			reply:    0x04,
+			policy:   RetryPolicy{MinBackoff: time.Millisecond},
+			attempts: 3,
+		},
+		{
+			name:     "Should make max attempts",
+			reply:    0x06,
+			policy:   RetryPolicy{MaxAttempts: 5, MinBackoff: time.Millisecond},
+			attempts: 5,
+		},
+		{
+			name:     "Should not retry reply which is not retryable by default",
+			reply:    0x05,
+			policy:   RetryPolicy{MinBackoff: time.Millisecond},
+			attempts: 1,
+		},
+		{
+			name:  "Should retry custom retryable reply",
+			reply: 0x05,
+			policy: RetryPolicy{
+				MinBackoff: time.Millisecond,
+				Retryable:  []SOCKSReply{ErrConnectionRefused},
+			},
+			attempts: 3,
+		},
+		{
+			name:  "Should not retry reply missing in custom retryable replies",
+			reply: 0x04,
+			policy: RetryPolicy{
+				MinBackoff: time.Millisecond,
+				Retryable:  []SOCKSReply{ErrConnectionRefused},
+			},
+			attempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			prx, requests := newTestProxy(t, tt.reply, tt.policy)
+
+			// act
+			_, err := prx.DialContext(context.Background(), "tcp", "example.com:80")
+
+			// assert
+			if !errors.Is(err, SOCKSReply(tt.reply)) {
+				t.Fatalf("got error %v, want %v", err, SOCKSReply(tt.reply))
+			}
+
+			if got := len(requests); got != tt.attempts {
+				t.Fatalf("got %d attempts, want %d", got, tt.attempts)
+			}
+		})
+	}
+
+	t.Run("Should stop retrying when context is done", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		prx, _ := newTestProxy(t, 0x04, RetryPolicy{MaxAttempts: 10, MinBackoff: time.Hour})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		t.Cleanup(cancel)
+
+		// act
+		_, err := prx.DialContext(ctx, "tcp", "example.com:80")
+
+		// assert
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		if !errors.Is(err, ErrHostUnreachable) {
+			t.Fatalf("got error %v, want %v", err, ErrHostUnreachable)
+		}
+	})
+}
+
+func TestRetryPolicy_NewCircuit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		commands int
+	}{
+		{
+			name:     "Should keep circuits by default",
+			policy:   RetryPolicy{MinBackoff: time.Millisecond},
+			commands: 0,
+		},
+		{
+			name:     "Should request new circuits before each retry",
+			policy:   RetryPolicy{MinBackoff: time.Millisecond, NewCircuit: true},
+			commands: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			addr, _ := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x04)
+			commands := make(chan string, 16)
+
+			ctrl := newTestController(t, func(srv *textproto.Conn) {
+				for {
+					line, err := srv.ReadLine()
+					if err != nil {
+						return
+					}
+
+					commands <- line
+					_ = srv.PrintfLine("250 OK")
+				}
+			})
+
+			prx, err := openSOCKS5Proxy(addr, nil, ctrl, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prx.retryPolicy = &tt.policy
+
+			// act
+			_, err = prx.DialContext(context.Background(), "tcp", "example.com:80")
+
+			// assert
+			if !errors.Is(err, ErrHostUnreachable) {
+				t.Fatalf("got error %v, want %v", err, ErrHostUnreachable)
+			}
+
+			if got := len(commands); got != tt.commands {
+				t.Fatalf("got %d control commands, want %d", got, tt.commands)
+			}
+
+			for range tt.commands {
+				if line := <-commands; line != "SIGNAL NEWNYM" {
+					t.Fatalf("unexpected command %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestFloatingProxy_DialContext_Retry(t *testing.T) {
+	t.Parallel()
+	t.Run("Should retry with next proxy of pool", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		failing, failed := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x04)
+		working, succeeded := newTestSOCKS5Server(t, "tcp", "127.0.0.1:0", 0x00)
+
+		pool := newFreePool(2, nil, nil)
+
+		for _, addr := range []endpoint{failing, working} {
+			prx, err := openSOCKS5Proxy(addr, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pool.add(prx)
+		}
+
+		floating := NewFloatingProxy(pool, WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}))
+
+		// act
+		conn, err := floating.DialContext(context.Background(), "tcp", "example.com:80")
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+		defer conn.Close()
+
+		if len(failed) != 1 || len(succeeded) != 1 {
+			t.Fatalf("got %d failed and %d succeeded attempts, want 1 and 1", len(failed), len(succeeded))
+		}
+	})
+
+	t.Run("Should use retry policy of pool by default", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pool := newTestPool(t, 1)
+		pool.retryPolicy = &RetryPolicy{MaxAttempts: 2}
+
+		// act
+		floating := NewFloatingProxy(pool)
+
+		// assert
+		if floating.retryPolicy != pool.retryPolicy {
+			t.Fatal("floating proxy should use retry policy of pool")
+		}
+	})
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// The constants of the SOCKS5 protocol, see RFC 1928 and the tor
+// extensions in https://spec.torproject.org/socks-extensions.html.
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone     = 0x00
+	socks5AuthPassword = 0x02
+	socks5AuthNoMethod = 0xFF
+
+	socks5CommandConnect    = 0x01
+	socks5CommandResolve    = 0xF0
+	socks5CommandResolvePTR = 0xF1
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded = 0x00
+)
+
+// socks5Addr is the address from the reply to the SOCKS5 command.
+type socks5Addr struct {
+	ip   netip.Addr
+	name string
+	port int
+}
+
+// dialSOCKS connects to the SocksPort of the tor demon using the forward
+// dialer, or directly if it is not set.
+func (p *Proxy) dialSOCKS(ctx context.Context) (net.Conn, error) {
+	var forward ContextDialer = &net.Dialer{}
+	if dr, ok := p.forward.(ContextDialer); ok {
+		forward = dr
+	}
+
+	conn, err := forward.DialContext(ctx, p.socks.network, p.socks.address)
+	if err != nil {
+		const format = "cannot connect to socks port %q: %w"
+		return nil, fmt.Errorf(format, p.socks.address, err)
+	}
+
+	return conn, nil
+}
+
+// socks5Exchange performs the greeting and the command on the connection
+// to the SocksPort, the context limits the duration of the exchange.
+func socks5Exchange(ctx context.Context, conn net.Conn, auth *proxy.Auth,
+	command byte, host string, port int,
+) (addr socks5Addr, err error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		// Unblocks reads and writes of the exchange.
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+
+	defer func() {
+		// The deadline may be broken by the context after the exchange.
+		if !stop() {
+			addr, err = socks5Addr{}, ctx.Err()
+		}
+	}()
+
+	if err := socks5Greeting(conn, auth); err != nil {
+		return socks5Addr{}, err
+	}
+
+	return socks5Command(conn, command, host, port)
+}
+
+// socks5Greeting negotiates the authentication method, the username and
+// password authentication is used if auth is not nil.
+func socks5Greeting(conn net.Conn, auth *proxy.Auth) error {
+	method := byte(socks5AuthNone)
+	if auth != nil {
+		method = socks5AuthPassword
+	}
+
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		const format = "cannot write socks5 greeting: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	var reply [2]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		const format = "cannot read socks5 greeting reply: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	if reply[0] != socks5Version {
+		const format = "unexpected socks version %d"
+		return fmt.Errorf(format, reply[0])
+	}
+
+	if reply[1] == socks5AuthNoMethod || reply[1] != method {
+		const format = "socks5 authentication method %d is not accepted"
+		return fmt.Errorf(format, method)
+	}
+
+	if auth == nil {
+		return nil
+	}
+
+	// Username/password authentication, RFC 1929.
+	msg := []byte{0x01, byte(len(auth.User))}
+	msg = append(msg, auth.User...)
+	msg = append(msg, byte(len(auth.Password)))
+	msg = append(msg, auth.Password...)
+
+	if _, err := conn.Write(msg); err != nil {
+		const format = "cannot write socks5 authentication: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		const format = "cannot read socks5 authentication reply: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	if reply[1] != 0x00 {
+		return errors.New("socks5 authentication failed")
+	}
+
+	return nil
+}
+
+// socks5Command sends the command and reads the reply to it.
+func socks5Command(conn net.Conn, command byte, host string, port int) (socks5Addr, error) {
+	msg := []byte{socks5Version, command, 0x00}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if ip.Is4() || ip.Is4In6() {
+			msg = append(msg, socks5AddrIPv4)
+			msg = append(msg, ip.Unmap().AsSlice()...)
+		} else {
+			msg = append(msg, socks5AddrIPv6)
+			msg = append(msg, ip.AsSlice()...)
+		}
+	} else {
+		if len(host) == 0 || len(host) > 255 {
+			const format = "invalid host name %q"
+			return socks5Addr{}, fmt.Errorf(format, host)
+		}
+
+		msg = append(msg, socks5AddrDomain, byte(len(host)))
+		msg = append(msg, host...)
+	}
+
+	msg = binary.BigEndian.AppendUint16(msg, uint16(port))
+
+	if _, err := conn.Write(msg); err != nil {
+		const format = "cannot write socks5 command: %v"
+		return socks5Addr{}, fmt.Errorf(format, err)
+	}
+
+	var reply [4]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		const format = "cannot read socks5 reply: %v"
+		return socks5Addr{}, fmt.Errorf(format, err)
+	}
+
+	if reply[0] != socks5Version {
+		const format = "unexpected socks version %d"
+		return socks5Addr{}, fmt.Errorf(format, reply[0])
+	}
+
+	if reply[1] != socks5ReplySucceeded {
+		return socks5Addr{}, SOCKSReply(reply[1])
+	}
+
+	return readSOCKS5Addr(conn, reply[3])
+}
+
+// readSOCKS5Addr reads the address of the type from the reply.
+func readSOCKS5Addr(r io.Reader, addrType byte) (addr socks5Addr, err error) {
+	var buf [255]byte
+
+	switch addrType {
+	case socks5AddrIPv4:
+		if _, err := io.ReadFull(r, buf[:4]); err != nil {
+			return socks5Addr{}, err
+		}
+
+		addr.ip = netip.AddrFrom4([4]byte(buf[:4]))
+	case socks5AddrIPv6:
+		if _, err := io.ReadFull(r, buf[:16]); err != nil {
+			return socks5Addr{}, err
+		}
+
+		addr.ip = netip.AddrFrom16([16]byte(buf[:16]))
+	case socks5AddrDomain:
+		if _, err := io.ReadFull(r, buf[:1]); err != nil {
+			return socks5Addr{}, err
+		}
+
+		size := int(buf[0])
+		if _, err := io.ReadFull(r, buf[:size]); err != nil {
+			return socks5Addr{}, err
+		}
+
+		addr.name = string(buf[:size])
+	default:
+		const format = "unknown socks5 address type %d"
+		return socks5Addr{}, fmt.Errorf(format, addrType)
+	}
+
+	if _, err := io.ReadFull(r, buf[:2]); err != nil {
+		return socks5Addr{}, err
+	}
+
+	addr.port = int(binary.BigEndian.Uint16(buf[:2]))
+
+	return addr, nil
+}
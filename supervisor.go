@@ -0,0 +1,330 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRestartMinBackoff = time.Second
+	defaultRestartMaxBackoff = time.Minute
+)
+
+// A RestartPolicy configures restarting of the tor demon, which exited
+// unexpectedly, e.g. it was killed by the OOM killer or crashed.
+type RestartPolicy struct {
+	// MaxAttempts is the maximum number of consecutive unsuccessful
+	// attempts to restart the tor demon, after which the supervisor gives
+	// up. Zero means no limit.
+	MaxAttempts int
+	// MinBackoff is the delay before the first attempt to restart the tor
+	// demon, it doubles after each unsuccessful attempt. If zero, one
+	// second is used.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between attempts to restart the tor
+	// demon. If zero, one minute is used.
+	MaxBackoff time.Duration
+}
+
+func (p RestartPolicy) minBackoff() time.Duration {
+	if p.MinBackoff <= 0 {
+		return defaultRestartMinBackoff
+	}
+
+	return p.MinBackoff
+}
+
+func (p RestartPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return defaultRestartMaxBackoff
+	}
+
+	return max(p.MaxBackoff, p.minBackoff())
+}
+
+// WithRestartPolicy enables supervision of the tor demon: if the tor demon
+// exits unexpectedly, it is restarted with the same torrc and ports, and
+// the Proxy and the Pool, including the proxies obtained from it, keep
+// working with the new tor demon. The Controller is reconnected to the new
+// tor demon too, but the state of the old one, such as onion services, is
+// lost.
+//
+// Without supervision, the Proxy and the Pool stop working when the tor
+// demon exits, see the Done and Err methods.
+func WithRestartPolicy(policy RestartPolicy) Option {
+	fun := func(s *options) {
+		s.restartPolicy = &policy
+	}
+
+	return optionFunc(fun)
+}
+
+// daemon is the tor demon launched in the background, it watches the
+// process and restarts it according to the restart policy.
+type daemon struct {
+	trc   torrc
+	state options
+
+	// ctx is used to launch the restarted tor demon, it is canceled to
+	// abort the launch when the daemon is stopped.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu guards the fields below.
+	mu      sync.Mutex
+	ctrl    *Controller
+	proc    *process
+	running bool
+	stopped bool
+	stop    chan struct{}
+
+	done chan struct{}
+	err  error
+}
+
+// process is the single run of the tor demon.
+type process struct {
+	cmd    *exec.Cmd
+	exited chan struct{}
+	err    error
+}
+
+// startDaemon starts watching the tor demon launched by
+// launchBackgroundTorDemon.
+func startDaemon(cmd *exec.Cmd, trc torrc, state options) *daemon {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := &daemon{
+		trc:    trc,
+		state:  state,
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	d.watch(cmd)
+
+	go d.run()
+
+	return d
+}
+
+// setController sets the Controller, which is reconnected to the restarted
+// tor demon.
+func (d *daemon) setController(ctrl *Controller) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.ctrl = ctrl
+}
+
+// watch waits for the process to exit in the background.
+func (d *daemon) watch(cmd *exec.Cmd) *process {
+	proc := &process{cmd: cmd, exited: make(chan struct{})}
+
+	d.mu.Lock()
+	d.proc = proc
+	d.running = true
+	d.mu.Unlock()
+
+	go func() {
+		defer close(proc.exited)
+
+		proc.err = cmd.Wait()
+	}()
+
+	return proc
+}
+
+func (d *daemon) run() {
+	defer close(d.done)
+
+	for {
+		d.mu.Lock()
+		proc := d.proc
+		d.mu.Unlock()
+
+		<-proc.exited
+
+		d.mu.Lock()
+		d.running = false
+		stopped := d.stopped
+		d.mu.Unlock()
+
+		if stopped {
+			return
+		}
+
+		err := proc.err
+		if err == nil {
+			err = errors.New("exited with zero status")
+		}
+
+		if d.state.restartPolicy == nil {
+			const format = "tornado: tor demon exited unexpectedly: %v"
+			d.err = fmt.Errorf(format, err)
+
+			return
+		}
+
+		if err := d.restart(); err != nil {
+			d.err = err
+			return
+		}
+
+		if d.isStopped() {
+			return
+		}
+	}
+}
+
+func (d *daemon) isStopped() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.stopped
+}
+
+// restart launches the new tor demon with exponential backoff.
+func (d *daemon) restart() error {
+	policy := d.state.restartPolicy
+	backoff := policy.minBackoff()
+
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-d.stop:
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		err := d.relaunch()
+		if err == nil || d.isStopped() {
+			return nil
+		}
+
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			const format = "tornado: cannot restart tor demon after %d attempts: %v"
+			return fmt.Errorf(format, attempt, err)
+		}
+
+		backoff = min(backoff*2, policy.maxBackoff())
+	}
+}
+
+func (d *daemon) relaunch() error {
+	cmd, err := launchBackgroundTorDemon(d.ctx, d.trc, d.state)
+	if err != nil {
+		return err
+	}
+
+	proc := d.watch(cmd)
+
+	ctrl, err := dialController(d.ctx, d.trc.controlAddress, CookieAuth(d.trc.cookieAuthFile))
+	if err != nil {
+		// The tor demon is useless without the control connection, it is
+		// stopped before the next attempt.
+		d.mu.Lock()
+		d.running = false
+		d.mu.Unlock()
+
+		_ = cmd.Process.Signal(os.Interrupt)
+		<-proc.exited
+
+		const format = "cannot open control connection: %v"
+
+		return fmt.Errorf(format, err)
+	}
+
+	d.mu.Lock()
+	current := d.ctrl
+	d.mu.Unlock()
+
+	if current != nil {
+		current.replace(ctrl)
+	} else {
+		_ = ctrl.Close()
+	}
+
+	return nil
+}
+
+// shutdown stops the tor demon and the supervision, it returns the error of
+// the tor demon exit.
+func (d *daemon) shutdown() error {
+	d.mu.Lock()
+
+	if !d.stopped {
+		d.stopped = true
+		close(d.stop)
+	}
+
+	proc, running := d.proc, d.running
+	d.mu.Unlock()
+
+	if running {
+		select {
+		case <-proc.exited:
+			// The tor demon exited by itself, its error is reported by
+			// exitError if it is not restarted.
+			running = false
+		default:
+		}
+	}
+
+	var err error
+
+	if running {
+		err = proc.cmd.Process.Signal(os.Interrupt)
+		if errors.Is(err, os.ErrProcessDone) {
+			err = nil
+		}
+	} else {
+		// The tor demon is waiting to be restarted or being launched.
+		d.cancel()
+	}
+
+	<-d.done
+	d.cancel()
+
+	if err != nil {
+		const format = "an error occurred while sending, a signal to interrupt" +
+			" the operation of the tor demon: %v"
+		return fmt.Errorf(format, err)
+	}
+
+	if running {
+		<-proc.exited
+
+		if proc.err != nil {
+			const format = "error while waiting is the result of sending," +
+				" a signal to interrupt the command: %v"
+			return fmt.Errorf(format, proc.err)
+		}
+	}
+
+	return nil
+}
+
+// exitError returns the reason why the tor demon exited unexpectedly, or
+// nil if it is running or was stopped by shutdown.
+func (d *daemon) exitError() error {
+	select {
+	case <-d.done:
+		return d.err
+	default:
+		return nil
+	}
+}
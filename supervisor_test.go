@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDaemon starts the daemon running the fake tor demon, which
+// bootstraps immediately and runs until it is interrupted.
+func newTestDaemon(t *testing.T, policy *RestartPolicy) (tor *daemon, ctrl *Controller, commands <-chan string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	binary := newFakeTorDemonBinary(t, "Oct 18 00:00:00.000 [notice] Bootstrapped 100% (done): Done")
+
+	cookie := filepath.Join(dir, "control_auth_cookie")
+	if err := os.WriteFile(cookie, []byte("cookie"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	controlAddr, commands := newTestControlServer(t)
+
+	addr, err := parseEndpoint(controlAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trc := torrc{
+		dataDirectory:  dir,
+		controlAddress: addr,
+		cookieAuthFile: cookie,
+		filename:       filepath.Join(dir, "torrc"),
+	}
+	state := options{torBinary: binary, restartPolicy: policy}
+
+	cmd, err := launchBackgroundTorDemon(context.Background(), trc, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tor = startDaemon(cmd, trc, state)
+	t.Cleanup(func() {
+		_ = tor.shutdown()
+	})
+
+	ctrl, err = dialController(context.Background(), addr, CookieAuth(cookie))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = ctrl.Close()
+	})
+
+	tor.setController(ctrl)
+
+	// The authentication of the first control connection.
+	<-commands
+
+	return tor, ctrl, commands
+}
+
+// newFakeTorDemonBinary creates the fake tor binary, which writes the lines
+// of the log to stderr and runs until it is interrupted.
+func newFakeTorDemonBinary(t *testing.T, log ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tor")
+	script := "#!/bin/sh\ntrap 'exit 0' INT\n"
+
+	for _, line := range log {
+		script += "echo '" + line + "' >&2\n"
+	}
+
+	script += "while :; do sleep 0.01; done\n"
+
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func killTestDaemon(t *testing.T, tor *daemon) {
+	t.Helper()
+
+	tor.mu.Lock()
+	proc := tor.proc
+	tor.mu.Unlock()
+
+	if err := proc.cmd.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	<-proc.exited
+}
+
+func TestWithRestartPolicy(t *testing.T) {
+	t.Parallel()
+	t.Run("Should restart tor demon and reconnect controller", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		tor, ctrl, commands := newTestDaemon(t, &RestartPolicy{MinBackoff: time.Millisecond})
+
+		ctrl.mu.Lock()
+		first := ctrl.conn
+		ctrl.mu.Unlock()
+
+		// act
+		killTestDaemon(t, tor)
+
+		// assert
+		if line := <-commands; !strings.HasPrefix(line, "AUTHENTICATE ") {
+			t.Fatalf("unexpected command %q", line)
+		}
+
+		for reconnected := false; !reconnected; time.Sleep(time.Millisecond) {
+			ctrl.mu.Lock()
+			reconnected = ctrl.conn != first
+			ctrl.mu.Unlock()
+		}
+
+		if err := ctrl.NewIdentity(context.Background()); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if line := <-commands; line != "SIGNAL NEWNYM" {
+			t.Fatalf("unexpected command %q", line)
+		}
+
+		if err := tor.shutdown(); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if err := tor.exitError(); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+	})
+
+	t.Run("Should report exit of tor demon without restart policy", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		tor, _, _ := newTestDaemon(t, nil)
+
+		// act
+		killTestDaemon(t, tor)
+
+		// assert
+		<-tor.done
+
+		if err := tor.exitError(); err == nil {
+			t.Fatal("an error was expected")
+		}
+	})
+
+	t.Run("Should stop tor demon waiting for restart", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		tor, _, _ := newTestDaemon(t, &RestartPolicy{MinBackoff: time.Hour})
+		killTestDaemon(t, tor)
+
+		// act
+		err := tor.shutdown()
+
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if err := tor.exitError(); err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+	})
+}
@@ -9,45 +9,84 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
-	"strings"
 )
 
-func launchBackgroundTorDemon(ctx context.Context, trc torrc) (cmd *exec.Cmd, err error) {
+func launchBackgroundTorDemon(ctx context.Context, trc torrc, state options) (cmd *exec.Cmd, err error) {
 	if ctx == nil {
 		panic("tornado: cannot create tor demon by nil context")
 	}
 
-	cmd = exec.CommandContext(ctx, "tor", "-f", trc.filename)
+	cmd = exec.CommandContext(ctx, state.torBinaryPath(), "-f", trc.filename)
 	cmd.Dir = trc.dataDirectory
 
-	stdoutPipe, err := cmd.StderrPipe()
+	// The pipe is created manually instead of using StderrPipe, so the log
+	// of the tor demon can be read after the launch, while the process is
+	// waited by the daemon.
+	stderr, stderrWriter, err := os.Pipe()
 	if err != nil {
-		const format = "failed to create stdout pipe for exec command %q: %v"
+		const format = "failed to create stderr pipe for exec command %q: %v"
 		return nil, fmt.Errorf(format, cmd.String(), err)
 	}
 
-	defer stdoutPipe.Close()
+	cmd.Stderr = stderrWriter
+
+	err = cmd.Start()
+	// The writer is owned by the tor demon since now.
+	_ = stderrWriter.Close()
+
+	if err != nil {
+		_ = stderr.Close()
 
-	if err := cmd.Start(); err != nil {
 		const format = "failed starting the command %q: %v"
+
 		return nil, fmt.Errorf(format, cmd.String(), err)
 	}
 
 	launchLog := bytes.NewBuffer(make([]byte, 0, 4096))
 	launched := make(chan error, 1)
+	// waited is set before the result of cmd.Wait is sent to launched.
+	waited := false
 
 	go func() {
-		defer close(launched)
+		defer stderr.Close()
+
+		bootstrapped := false
 
-		scanner := bufio.NewScanner(stdoutPipe)
+		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
 			text := scanner.Text()
+			state.logTorLine(text)
+
+			if bootstrapped {
+				continue
+			}
+
 			launchLog.WriteString(text)
+			launchLog.WriteString("\n")
 
-			if strings.Contains(text, "Bootstrapped 100%") {
-				return
+			status, ok := parseBootstrapStatus(text)
+			if !ok {
+				continue
 			}
+
+			if state.bootstrapProgress != nil {
+				state.bootstrapProgress(status)
+			}
+
+			if status.Progress == 100 {
+				bootstrapped = true
+				launched <- nil
+			}
+		}
+
+		if bootstrapped {
+			// The log must be read until the tor demon exits, otherwise
+			// the pipe fills up and blocks the tor demon.
+			_, _ = io.Copy(io.Discard, stderr)
+			return
 		}
 
 		if err := scanner.Err(); err != nil {
@@ -57,15 +96,28 @@ func launchBackgroundTorDemon(ctx context.Context, trc torrc) (cmd *exec.Cmd, er
 			return
 		}
 
-		launched <- cmd.Wait()
+		err := cmd.Wait()
+		waited = true
+		launched <- err
 	}()
 
 	select {
 	case <-ctx.Done():
 		err = ctx.Err()
+		// The process is killed because the context is done, the value
+		// is received to synchronize with the goroutine.
+		<-launched
 	case err = <-launched:
 	}
 
+	if err != nil && !waited {
+		// The tor demon may still be running, e.g. the bootstrapping
+		// finished while the context was being canceled, so it is killed
+		// and waited for, so that its data directory can be removed.
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}
+
 	if err != nil {
 		const format = "failed running the command %q: %v" +
 			"\n\n# Torrc file:\n%s" +
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestLaunchBackgroundTorDemon(t *testing.T) {
+	t.Parallel()
+	t.Run("Should reap tor demon when context is canceled at the end of bootstrapping", func(t *testing.T) {
+		t.Parallel()
+
+		// The context is canceled when the bootstrapping is finished, so
+		// both outcomes of the launch are possible, it is repeated to get
+		// the canceled one.
+		for range 20 {
+			// arrange
+			dir := t.TempDir()
+			pidFile := filepath.Join(dir, "pid")
+			binary := filepath.Join(dir, "tor")
+
+			script := "#!/bin/sh\n" +
+				"echo $$ > " + pidFile + "\n" +
+				"echo 'Oct 18 00:00:00.000 [notice] Bootstrapped 100% (done): Done' >&2\n" +
+				"while :; do sleep 0.01; done\n"
+
+			if err := os.WriteFile(binary, []byte(script), 0o700); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			state := options{
+				torBinary: binary,
+				bootstrapProgress: func(status BootstrapStatus) {
+					if status.Progress == 100 {
+						cancel()
+					}
+				},
+			}
+			trc := torrc{dataDirectory: dir, filename: filepath.Join(dir, "torrc")}
+
+			// act
+			cmd, err := launchBackgroundTorDemon(ctx, trc, state)
+
+			// assert
+			if err == nil {
+				// The bootstrapping won the race, the process is killed anyway.
+				_ = cmd.Wait()
+				continue
+			}
+
+			if !strings.Contains(err.Error(), context.Canceled.Error()) {
+				t.Fatalf("got error %v, want %v", err, context.Canceled)
+			}
+
+			data, readErr := os.ReadFile(pidFile)
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+
+			pid, convErr := strconv.Atoi(strings.TrimSpace(string(data)))
+			if convErr != nil {
+				t.Fatal(convErr)
+			}
+
+			// The signal 0 reaches zombies, so ESRCH means the process was
+			// waited for.
+			if err := syscall.Kill(pid, 0); !errors.Is(err, syscall.ESRCH) {
+				t.Fatalf("process %d should be reaped, got %v", pid, err)
+			}
+		}
+	})
+}
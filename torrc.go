@@ -6,56 +6,184 @@ package tornado
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/xorcare/tornado/internal/freeport"
 )
 
 type torrc struct {
 	dataDirectory string
+	// ownsDataDirectory is true if the data directory was created by
+	// tornado, and must be removed after the tor demon exits.
+	ownsDataDirectory bool
+	lockFile          string
 
-	socksPort    []int
-	customOption []string
-	afterOption  []string
+	socksAddress   []endpoint
+	controlAddress endpoint
+	customOption   []string
+	afterOption    []string
+
+	// httpTunnelAddress is empty if the HTTP tunnel is not enabled,
+	// otherwise it has the address for each SOCKS address.
+	httpTunnelAddress []endpoint
+	// dnsAddress is empty if the DNS port is not enabled.
+	dnsAddress endpoint
 
 	torrc    string
 	filename string
+
+	cookieAuthFile     string
+	clientOnionAuthDir string
+}
+
+// cleanup removes the data directory if it was created by tornado,
+// directories supplied by the user are left intact, only the torrc and
+// lock files are removed from them.
+func (trc torrc) cleanup() error {
+	if trc.ownsDataDirectory {
+		if err := os.RemoveAll(trc.dataDirectory); err != nil {
+			const format = "cannot remove data directory of tor demon: %v"
+			return fmt.Errorf(format, err)
+		}
+
+		return nil
+	}
+
+	for _, name := range []string{trc.filename, trc.lockFile} {
+		if name == "" {
+			continue
+		}
+
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			const format = "cannot remove file from data directory of tor demon: %v"
+			return fmt.Errorf(format, err)
+		}
+	}
+
+	return nil
 }
 
 func newTorrcFromState(state options) (trc torrc, err error) {
 	trc = torrc{
 		afterOption: []string{
 			// Recognized severity levels are debug, info, notice, warn, and err.
-			// Log level must be "notice" for working startup trap.
-			"Log notice stderr",
+			// Log level must be "notice" or lower for working startup trap.
+			fmt.Sprintf("Log %s stderr", state.torLogSeverity()),
 			"RunAsDaemon 0",
 		},
 	}
 
-	ports, err := freeport.Much(state.numberOfProxy)
-	if err != nil {
-		const format = "cannot get free ports for tor proxy: %v"
-		return torrc{}, fmt.Errorf(format, err)
+	if state.numberOfProxy < 1 {
+		const format = "not possible to create less than one proxy, got %d"
+		return torrc{}, fmt.Errorf(format, state.numberOfProxy)
+	}
+
+	if err := state.config.Validate(); err != nil {
+		return torrc{}, err
 	}
 
-	trc.socksPort = append(trc.socksPort, ports...)
+	trc.customOption = append(trc.customOption, state.config.torrcLines()...)
 	trc.customOption = append(trc.customOption, state.torrcOptions...)
 
-	dir := fmt.Sprintf("tornado.%d.*", os.Getpid())
+	if state.dataDirectory != "" {
+		trc.dataDirectory, err = filepath.Abs(state.dataDirectory)
+		if err != nil {
+			const format = "cannot resolve data directory path: %v"
+			return torrc{}, fmt.Errorf(format, err)
+		}
+
+		if err := os.MkdirAll(trc.dataDirectory, 0o700); err != nil {
+			const format = "cannot create data directory: %v"
+			return torrc{}, fmt.Errorf(format, err)
+		}
+
+		trc.lockFile, err = lockDataDirectory(trc.dataDirectory)
+		if err != nil {
+			const format = "cannot lock data directory: %w"
+			return torrc{}, fmt.Errorf(format, err)
+		}
+	} else {
+		dir := fmt.Sprintf("tornado.%d.*", os.Getpid())
+
+		trc.dataDirectory, err = os.MkdirTemp("", dir)
+		if err != nil {
+			const format = "cannot create temp dir for tor proxy: %v"
+			return torrc{}, fmt.Errorf(format, err)
+		}
+
+		trc.ownsDataDirectory = true
+	}
+
+	// Files created so far must be removed if the torrc can't be created.
+	created := trc
+
+	defer func() {
+		if err != nil {
+			_ = created.cleanup()
+		}
+	}()
+
+	if state.unixSockets {
+		trc.socksAddress, trc.controlAddress, err = unixEndpoints(trc.dataDirectory, state.numberOfProxy)
+	} else {
+		trc.socksAddress, trc.controlAddress, err = tcpEndpoints(state.numberOfProxy)
+	}
 
-	trc.dataDirectory, err = os.MkdirTemp("", dir)
 	if err != nil {
-		const format = "cannot create temp dir for tor proxy: %v"
-		return torrc{}, fmt.Errorf(format, err)
+		return torrc{}, err
+	}
+
+	if state.httpTunnel {
+		trc.httpTunnelAddress, err = httpTunnelEndpoints(state.numberOfProxy)
+		if err != nil {
+			return torrc{}, err
+		}
+	}
+
+	if state.dnsPort {
+		trc.dnsAddress, err = dnsEndpoint()
+		if err != nil {
+			return torrc{}, err
+		}
+	}
+
+	trc.cookieAuthFile = filepath.Join(trc.dataDirectory, "control_auth_cookie")
+
+	if len(state.onionClientAuth) > 0 {
+		trc.clientOnionAuthDir = filepath.Join(trc.dataDirectory, "onion_auth")
+
+		err := writeClientOnionAuthDir(trc.clientOnionAuthDir, state.onionClientAuth)
+		if err != nil {
+			const format = "cannot configure onion client auth: %v"
+			return torrc{}, fmt.Errorf(format, err)
+		}
 	}
 
 	buf := bytes.NewBuffer(make([]byte, 0, 4096))
 
 	fmt.Fprintf(buf, "DataDirectory %s\n\n", trc.dataDirectory)
 
-	for _, port := range trc.socksPort {
-		fmt.Fprintf(buf, "SocksPort %d\n\n", port)
+	for _, addr := range trc.socksAddress {
+		fmt.Fprintf(buf, "SocksPort %s ExtendedErrors\n\n", addr.torrcValue())
+	}
+
+	for _, addr := range trc.httpTunnelAddress {
+		fmt.Fprintf(buf, "HTTPTunnelPort %s\n\n", addr.torrcValue())
+	}
+
+	if trc.dnsAddress.address != "" {
+		fmt.Fprintf(buf, "DNSPort %s\n\n", trc.dnsAddress.address)
+	}
+
+	fmt.Fprintf(buf, "ControlPort %s\n", trc.controlAddress.torrcValue())
+	fmt.Fprintf(buf, "CookieAuthentication 1\n")
+	fmt.Fprintf(buf, "CookieAuthFile %s\n\n", trc.cookieAuthFile)
+
+	if trc.clientOnionAuthDir != "" {
+		fmt.Fprintf(buf, "ClientOnionAuthDir %s\n\n", trc.clientOnionAuthDir)
 	}
 
 	for _, option := range trc.customOption {
@@ -76,6 +204,8 @@ func newTorrcFromState(state options) (trc torrc, err error) {
 		return torrc{}, fmt.Errorf(format, err)
 	}
 
+	created.filename = tempFile.Name()
+
 	if _, err := tempFile.WriteString(trc.torrc); err != nil {
 		const format = "cannot write temp torrc file: %v"
 		return torrc{}, fmt.Errorf(format, err)
@@ -90,3 +220,40 @@ func newTorrcFromState(state options) (trc torrc, err error) {
 
 	return trc, nil
 }
+
+// tcpEndpoints returns the addresses of SOCKS and control ports of the tor
+// demon on free TCP ports.
+func tcpEndpoints(number int) (socks []endpoint, control endpoint, err error) {
+	// One extra port is needed for the control port.
+	ports, err := freeport.Much(number + 1)
+	if err != nil {
+		const format = "cannot get free ports for tor proxy: %v"
+		return nil, endpoint{}, fmt.Errorf(format, err)
+	}
+
+	for _, port := range ports[:number] {
+		socks = append(socks, tcpEndpoint(port))
+	}
+
+	return socks, tcpEndpoint(ports[number]), nil
+}
+
+// unixEndpoints returns the addresses of SOCKS and control ports of the tor
+// demon on unix sockets in the directory.
+func unixEndpoints(dir string, number int) (socks []endpoint, control endpoint, err error) {
+	for i := range number {
+		addr, err := unixEndpoint(dir, fmt.Sprintf("socks.%d.sock", i))
+		if err != nil {
+			return nil, endpoint{}, err
+		}
+
+		socks = append(socks, addr)
+	}
+
+	control, err = unixEndpoint(dir, "control.sock")
+	if err != nil {
+		return nil, endpoint{}, err
+	}
+
+	return socks, control, nil
+}
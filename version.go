@@ -0,0 +1,178 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultTorBinary = "tor"
+
+// A Version is a version of tor in the format described in the tor
+// version-spec, e.g. "0.4.8.10" or "0.4.9.1-alpha".
+type Version struct {
+	Major int
+	Minor int
+	Micro int
+	Patch int
+	// Status is the optional status tag, e.g. "alpha", "rc" or "dev".
+	Status string
+}
+
+var (
+	versionRegexp    = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:\.(\d+))?(?:-([0-9A-Za-z]+))?$`)
+	torVersionRegexp = regexp.MustCompile(`Tor version (\d+\.\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z]+)?)`)
+)
+
+// ParseVersion parses the tor version string.
+func ParseVersion(s string) (Version, error) {
+	match := versionRegexp.FindStringSubmatch(s)
+	if match == nil {
+		const format = "tornado: malformed tor version %q"
+		return Version{}, fmt.Errorf(format, s)
+	}
+
+	var v Version
+
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	v.Micro, _ = strconv.Atoi(match[3])
+	v.Patch, _ = strconv.Atoi(match[4])
+	v.Status = match[5]
+
+	return v, nil
+}
+
+// Compare returns -1 if v is older than w, +1 if v is newer than w and 0
+// if they are the same. A version without a status tag is newer than
+// the same version with the tag.
+func (v Version) Compare(w Version) int {
+	if c := cmp.Compare(v.Major, w.Major); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.Micro, w.Micro); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.Patch, w.Patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case v.Status == w.Status:
+		return 0
+	case v.Status == "":
+		return 1
+	case w.Status == "":
+		return -1
+	default:
+		return strings.Compare(v.Status, w.Status)
+	}
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Micro, v.Patch)
+	if v.Status != "" {
+		s += "-" + v.Status
+	}
+
+	return s
+}
+
+// A VersionError is returned by NewProxy and NewPool when the tor binary
+// is older than the version specified via WithMinimumTorVersion.
+type VersionError struct {
+	Binary  string
+	Version Version
+	Minimum Version
+}
+
+func (e *VersionError) Error() string {
+	const format = "tornado: tor binary %q has version %s, but at least %s is required"
+	return fmt.Sprintf(format, e.Binary, e.Version, e.Minimum)
+}
+
+// TorVersion returns the version of the tor binary located by the path,
+// if the path is empty, the tor binary is looked up in the PATH.
+func TorVersion(ctx context.Context, path string) (Version, error) {
+	if ctx == nil {
+		panic("tornado: nil context")
+	}
+
+	if path == "" {
+		path = defaultTorBinary
+	}
+
+	cmd := exec.CommandContext(ctx, path, "--version")
+
+	out, err := cmd.Output()
+	if err != nil {
+		const format = "tornado: failed running the command %q: %v"
+		return Version{}, fmt.Errorf(format, cmd.String(), err)
+	}
+
+	match := torVersionRegexp.FindSubmatch(out)
+	if match == nil {
+		const format = "tornado: no tor version in output of the command %q: %q"
+		return Version{}, fmt.Errorf(format, cmd.String(), out)
+	}
+
+	return ParseVersion(string(match[1]))
+}
+
+// WithTorBinary allows to specify the path to the tor binary, by default
+// the tor binary is looked up in the PATH.
+func WithTorBinary(path string) Option {
+	fun := func(s *options) {
+		s.torBinary = path
+	}
+
+	return optionFunc(fun)
+}
+
+// WithMinimumTorVersion makes NewProxy and NewPool fail with *VersionError
+// before launching the tor demon when the tor binary is older than
+// the version.
+func WithMinimumTorVersion(v Version) Option {
+	fun := func(s *options) {
+		s.minimumTorVersion = &v
+	}
+
+	return optionFunc(fun)
+}
+
+// checkTorVersion checks that the tor binary satisfies the minimum version
+// if it is specified.
+func checkTorVersion(ctx context.Context, state options) error {
+	if state.minimumTorVersion == nil {
+		return nil
+	}
+
+	v, err := TorVersion(ctx, state.torBinary)
+	if err != nil {
+		return err
+	}
+
+	if v.Compare(*state.minimumTorVersion) < 0 {
+		return &VersionError{
+			Binary:  state.torBinaryPath(),
+			Version: v,
+			Minimum: *state.minimumTorVersion,
+		}
+	}
+
+	return nil
+}
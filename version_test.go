@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Vasiliy Vasilyuk. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tornado
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newFakeTorBinary creates an executable script printing the output
+// of tor --version for the version.
+func newFakeTorBinary(t *testing.T, version string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tor")
+	script := "#!/bin/sh\necho 'Tor version " + version + ".'\n"
+
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		version string
+		want    Version
+		wantErr bool
+	}{
+		{
+			name:    "Should parse release version",
+			version: "0.4.8.10",
+			want:    Version{Major: 0, Minor: 4, Micro: 8, Patch: 10},
+		},
+		{
+			name:    "Should parse version with status tag",
+			version: "0.4.9.1-alpha",
+			want:    Version{Major: 0, Minor: 4, Micro: 9, Patch: 1, Status: "alpha"},
+		},
+		{
+			name:    "Should parse version without patch level",
+			version: "0.4.8",
+			want:    Version{Major: 0, Minor: 4, Micro: 8},
+		},
+		{
+			name:    "Should reject malformed version",
+			version: "0.4.x",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		v, w string
+		want int
+	}{
+		{v: "0.4.8.10", w: "0.4.8.10", want: 0},
+		{v: "0.4.8.9", w: "0.4.8.10", want: -1},
+		{v: "0.4.9.1", w: "0.4.8.10", want: 1},
+		{v: "0.4.9.1-alpha", w: "0.4.9.1", want: -1},
+		{v: "0.4.9.1-rc", w: "0.4.9.1-alpha", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.v+" vs "+tt.w, func(t *testing.T) {
+			t.Parallel()
+
+			v, _ := ParseVersion(tt.v)
+			w, _ := ParseVersion(tt.w)
+
+			if got := v.Compare(w); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTorVersion(t *testing.T) {
+	t.Parallel()
+	t.Run("Should parse output of tor --version", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		path := newFakeTorBinary(t, "0.4.8.10")
+
+		// act
+		got, err := TorVersion(context.Background(), path)
+		// assert
+		if err != nil {
+			t.Fatal("should not get an error:", err)
+		}
+
+		if got.String() != "0.4.8.10" {
+			t.Fatalf("got version %s, want 0.4.8.10", got)
+		}
+	})
+}
+
+func TestWithMinimumTorVersion(t *testing.T) {
+	t.Parallel()
+	t.Run("Should fail early when tor is older than minimum version", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		path := newFakeTorBinary(t, "0.4.7.16")
+		minimum := Version{Major: 0, Minor: 4, Micro: 8}
+
+		// act
+		_, err := NewProxy(
+			context.Background(),
+			WithTorBinary(path),
+			WithMinimumTorVersion(minimum),
+		)
+
+		// assert
+		var versionErr *VersionError
+		if !errors.As(err, &versionErr) {
+			t.Fatalf("*VersionError was expected, but got another one: %v", err)
+		}
+
+		if versionErr.Minimum != minimum {
+			t.Fatalf("got minimum version %s, want %s", versionErr.Minimum, minimum)
+		}
+	})
+}